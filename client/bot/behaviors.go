@@ -0,0 +1,74 @@
+package bot
+
+import (
+	"math"
+	"math/rand/v2"
+
+	"github.com/z46-dev/game-dev-project/shared/protocol"
+	"github.com/z46-dev/game-dev-project/util"
+)
+
+// Movement keys that point closest to a direction
+func FlagsToward(dx, dy float64) (flags uint8) {
+	if math.Hypot(dx, dy) == 0 {
+		return
+	}
+
+	var angle float64 = math.Atan2(dy, dx)
+	var cos, sin float64 = math.Cos(angle), math.Sin(angle)
+
+	if cos > 0.38 {
+		flags |= protocol.BITFLAG_INPUT_RIGHT
+	} else if cos < -0.38 {
+		flags |= protocol.BITFLAG_INPUT_LEFT
+	}
+
+	if sin > 0.38 {
+		flags |= protocol.BITFLAG_INPUT_DOWN
+	} else if sin < -0.38 {
+		flags |= protocol.BITFLAG_INPUT_UP
+	}
+
+	return
+}
+
+// Sits still
+func Idle() Behavior {
+	return func(world *World) (uint8, *util.Vector2D) {
+		return 0, nil
+	}
+}
+
+// Holds a random heading, picking a new one every few seconds at random
+func Wander(seed uint64, tickRate int) Behavior {
+	var (
+		rng     *rand.Rand = rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))
+		flags   uint8
+		ticks   int
+		between int = max(1, tickRate) * 3
+	)
+
+	return func(world *World) (uint8, *util.Vector2D) {
+		if ticks--; ticks <= 0 {
+			var angle float64 = rng.Float64() * math.Pi * 2
+			flags, ticks = FlagsToward(math.Cos(angle), math.Sin(angle)), between/2+rng.IntN(between)
+		}
+
+		return flags, nil
+	}
+}
+
+// Steers toward the nearest ship in view and aims at it, wandering while nothing is around
+func Chase(seed uint64, tickRate int) Behavior {
+	var wander Behavior = Wander(seed, tickRate)
+
+	return func(world *World) (uint8, *util.Vector2D) {
+		var target *Ship = world.Nearest()
+		if target == nil {
+			return wander(world)
+		}
+
+		var center *util.Vector2D = world.Center()
+		return FlagsToward(target.X-center.X, target.Y-center.Y), util.Vector(target.X, target.Y)
+	}
+}
@@ -0,0 +1,213 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/z46-dev/game-dev-project/client/web"
+	"github.com/z46-dev/game-dev-project/shared/protocol"
+	"github.com/z46-dev/game-dev-project/util"
+)
+
+const DEFAULT_JOIN_TIMEOUT time.Duration = 10 * time.Second
+
+var (
+	ErrDial        = errors.New("dial failed")
+	ErrRejected    = errors.New("join rejected")
+	ErrJoinTimeout = errors.New("join timed out")
+	ErrClosed      = errors.New("connection closed before the join was accepted")
+)
+
+// Dials the server and joins, returning once the join is accepted or has failed
+func Connect(config Config) (b *Bot, err error) {
+	var timeout time.Duration = config.JoinTimeout
+	if timeout <= 0 {
+		timeout = DEFAULT_JOIN_TIMEOUT
+	}
+
+	var dialer websocket.Dialer = websocket.Dialer{HandshakeTimeout: timeout}
+	conn, _, err := dialer.Dial(config.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDial, err)
+	}
+
+	b = &Bot{
+		Config:       config,
+		Socket:       web.NewSocket(conn),
+		World:        NewWorld(),
+		Stats:        &Stats{},
+		Disconnected: make(chan struct{}),
+		accepted:     make(chan error, 1),
+	}
+
+	b.Socket.OnClose = b.markDisconnected
+	go func() {
+		b.Socket.InitiateUpdateLoop(b.handle)
+		b.markDisconnected()
+	}()
+
+	var w *protocol.Writer = protocol.NewWriter(protocol.PROTOCOL_VERSION)
+	w.SetU8(protocol.PACKET_SERVERBOUND_JOIN)
+	w.SetU16(protocol.PROTOCOL_VERSION)
+	(&protocol.Join{Name: config.Name, ShipID: config.ShipID, Room: config.Room, Token: config.Token, Loadout: config.Loadout}).Write(w)
+	b.send(w)
+
+	select {
+	case err = <-b.accepted:
+	case <-b.Disconnected:
+		// A rejection arrives right before the server hangs up
+		select {
+		case err = <-b.accepted:
+		default:
+			err = ErrClosed
+		}
+	case <-time.After(timeout):
+		err = ErrJoinTimeout
+	}
+
+	if err != nil {
+		b.Close()
+		return nil, err
+	}
+
+	return b, nil
+}
+
+func (b *Bot) markDisconnected() {
+	b.closeOnce.Do(func() {
+		close(b.Disconnected)
+	})
+}
+
+func (b *Bot) send(w *protocol.Writer) {
+	var bytes []byte = w.GetBytes()
+	if b.Socket.Write(bytes) == nil {
+		b.Stats.BytesOut.Add(int64(len(bytes)))
+	}
+}
+
+func (b *Bot) handle(message []byte) {
+	b.Stats.BytesIn.Add(int64(len(message)))
+	b.Stats.MessagesIn.Add(1)
+
+	var reader *protocol.Reader = protocol.NewReader(message)
+	var packetType uint8 = reader.GetU8()
+	reader.Version = protocol.PROTOCOL_VERSION
+
+	switch packetType {
+	case protocol.PACKET_CLIENTBOUND_JOIN_ACCEPT:
+		var accept protocol.JoinAccept
+		if err := accept.Read(reader); err != nil {
+			b.accept(fmt.Errorf("malformed join accept: %w", err))
+			return
+		}
+
+		b.PlayerID = accept.PlayerID
+		b.TickRate = int(accept.TickRate)
+
+		b.World.mu.Lock()
+		b.World.PositionPrecision = float64(accept.PositionPrecision)
+		b.World.mu.Unlock()
+
+		b.accept(nil)
+	case protocol.PACKET_CLIENTBOUND_JOIN_REJECT, protocol.PACKET_CLIENTBOUND_KICK:
+		var disconnect protocol.Disconnect
+		if disconnect.Read(reader) == nil {
+			b.Reason = disconnect.Reason
+		}
+
+		b.accept(fmt.Errorf("%w: %s", ErrRejected, b.Reason))
+	case protocol.PACKET_CLIENTBOUND_VIEW_UPDATE:
+		if b.World.ParseViewUpdate(reader) == nil {
+			b.Stats.observeView(b.World.Tick, time.Now())
+		}
+	}
+}
+
+// Only the first outcome of the join counts, a later kick is not a join result
+func (b *Bot) accept(err error) {
+	select {
+	case b.accepted <- err:
+	default:
+	}
+}
+
+// Sends one input per server tick from the behavior until stop closes or the connection ends
+func (b *Bot) Run(stop <-chan struct{}) {
+	var ticker *time.Ticker = time.NewTicker(time.Second / time.Duration(max(1, b.TickRate)))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-b.Disconnected:
+			return
+		case <-ticker.C:
+			b.SendInput()
+		}
+	}
+}
+
+// Asks the behavior what to do and sends it as a sequenced input
+func (b *Bot) SendInput() {
+	var (
+		flags  uint8
+		target *util.Vector2D
+	)
+
+	if b.Config.Behavior != nil {
+		flags, target = b.Config.Behavior(b.World)
+	}
+
+	b.sequence++
+	var input *protocol.Input = &protocol.Input{Flags: flags, Sequence: b.sequence}
+
+	b.World.mu.RLock()
+	input.ViewTick = b.World.Tick
+	b.World.mu.RUnlock()
+
+	if target != nil {
+		input.Flags |= protocol.BITFLAG_MOUSE_MOVE
+		input.MouseX, input.MouseY = float32(target.X), float32(target.Y)
+	}
+
+	var w *protocol.Writer = protocol.NewWriter(protocol.PROTOCOL_VERSION)
+	w.SetU8(protocol.PACKET_SERVERBOUND_INPUT)
+	input.Write(w)
+	b.send(w)
+}
+
+func (b *Bot) Close() {
+	b.Socket.Close()
+	b.markDisconnected()
+}
+
+// Wall time per server tick, measured between view updates
+func (s *Stats) observeView(tick uint32, now time.Time) {
+	s.ViewUpdates.Add(1)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.lastView.IsZero() && tick > s.lastTick {
+		var perTick time.Duration = now.Sub(s.lastView) / time.Duration(tick-s.lastTick)
+		s.tickTotal += perTick
+		s.tickWorst = max(s.tickWorst, perTick)
+		s.tickSamples++
+	}
+
+	s.lastView, s.lastTick = now, tick
+}
+
+// Tick times seen since the last call
+func (s *Stats) TakeTickTimes() (total, worst time.Duration, samples int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	total, worst, samples = s.tickTotal, s.tickWorst, s.tickSamples
+	s.tickTotal, s.tickWorst, s.tickSamples = 0, 0, 0
+	return
+}
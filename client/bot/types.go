@@ -0,0 +1,75 @@
+// Headless client for load tests and scripted play. Speaks the latest protocol without Ebiten: it joins,
+// keeps a lightweight model of what the server shows it and sends one input per tick from a Behavior.
+package bot
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/z46-dev/game-dev-project/client/web"
+	"github.com/z46-dev/game-dev-project/util"
+)
+
+type (
+	// Decides a bot's input every tick from what it can see
+	Behavior func(world *World) (flags uint8, target *util.Vector2D)
+
+	Config struct {
+		URL, Name, Room string
+		Token           string  // Session token to join with, empty for a guest
+		Loadout         []uint8 // Squadron variant per slot, empty for the ship's defaults
+		ShipID          uint8
+		Behavior        Behavior      // nil sits still
+		JoinTimeout     time.Duration // 0 for DEFAULT_JOIN_TIMEOUT
+	}
+
+	Ship struct {
+		ID                           uint64
+		ShipID                       uint8
+		Name                         string
+		X, Y, Size, Rotation, Health float64
+	}
+
+	Island struct {
+		ID                   uint64
+		X, Y, Size, Rotation float64
+	}
+
+	// Everything the latest view updates said, guarded by mu since the socket writes while behaviors read
+	World struct {
+		mu                sync.RWMutex
+		Tick              uint32
+		X, Y, FOV         float64 // Camera
+		EntityID          uint64  // Own ship, 0 while spectating
+		PositionPrecision float64
+		Ships             map[uint64]*Ship
+		Islands           map[uint64]*Island
+	}
+
+	// Counters a load test reads while the bot runs
+	Stats struct {
+		BytesIn, BytesOut, MessagesIn, ViewUpdates atomic.Int64
+
+		mu                   sync.Mutex
+		lastView             time.Time
+		lastTick             uint32
+		tickTotal, tickWorst time.Duration
+		tickSamples          int
+	}
+
+	Bot struct {
+		Config       Config
+		Socket       *web.Socket
+		World        *World
+		Stats        *Stats
+		PlayerID     uint32
+		TickRate     int
+		Disconnected chan struct{} // Closed when the connection ends for any reason
+		Reason       string        // Why the server disconnected the bot, if it said
+
+		accepted  chan error
+		sequence  uint32
+		closeOnce sync.Once
+	}
+)
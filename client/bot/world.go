@@ -0,0 +1,174 @@
+package bot
+
+import (
+	"math"
+
+	"github.com/z46-dev/game-dev-project/shared/protocol"
+	"github.com/z46-dev/game-dev-project/util"
+)
+
+func NewWorld() (w *World) {
+	w = &World{
+		PositionPrecision: 1,
+		Ships:             make(map[uint64]*Ship),
+		Islands:           make(map[uint64]*Island),
+	}
+
+	return
+}
+
+// Applies a PACKET_CLIENTBOUND_VIEW_UPDATE, stopping at the first decode error
+func (w *World) ParseViewUpdate(reader *protocol.Reader) (err error) {
+	var header protocol.ViewHeader
+	if err = header.Read(reader); err != nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.Tick = header.Time
+	w.X, w.Y, w.FOV = float64(header.X), float64(header.Y), float64(header.FOV)
+	w.EntityID = header.EntityID
+
+	for id := reader.GetVarU64(); id != 0 && reader.Err() == nil; id = reader.GetVarU64() {
+		var entityType uint8 = reader.GetU8()
+		var isNew bool = reader.GetU8() == 0
+
+		switch entityType {
+		case protocol.ENTITY_TYPE_SHIP:
+			w.parseShip(reader, id, isNew)
+		case protocol.ENTITY_TYPE_ISLAND:
+			w.parseIsland(reader, id, isNew)
+		default:
+			return reader.Err()
+		}
+	}
+
+	for id := reader.GetVarU64(); id != 0; id = reader.GetVarU64() {
+		switch reader.GetU8() {
+		case protocol.ENTITY_TYPE_SHIP:
+			delete(w.Ships, id)
+		case protocol.ENTITY_TYPE_ISLAND:
+			delete(w.Islands, id)
+		}
+	}
+
+	// Held back ships keep their last known state, the list only has to be consumed
+	for reader.GetVarU64() != 0 {
+	}
+
+	return reader.Err()
+}
+
+func (w *World) dequantize(offsetX, offsetY uint16) (x, y float64) {
+	return protocol.DequantizeOffset(offsetX, w.X, w.PositionPrecision), protocol.DequantizeOffset(offsetY, w.Y, w.PositionPrecision)
+}
+
+func (w *World) parseShip(reader *protocol.Reader, id uint64, isNew bool) {
+	if isNew {
+		var create protocol.ShipCreate
+		if create.Read(reader) != nil {
+			return
+		}
+
+		var ship *Ship = &Ship{
+			ID:       id,
+			ShipID:   create.ShipID,
+			Name:     create.Name,
+			Size:     float64(create.Size),
+			Rotation: protocol.DequantizeAngle12(create.Angle),
+			Health:   float64(create.Health),
+		}
+
+		ship.X, ship.Y = w.dequantize(create.OffsetX, create.OffsetY)
+		w.Ships[id] = ship
+		return
+	}
+
+	var update protocol.ShipUpdate
+	if update.Read(reader) != nil {
+		return
+	}
+
+	var ship *Ship = w.Ships[id]
+	if ship == nil {
+		return
+	}
+
+	if update.Flags&protocol.BITFLAG_SHIP_UPDATE_POSITION != 0 {
+		ship.X, ship.Y = w.dequantize(update.OffsetX, update.OffsetY)
+	}
+
+	if update.Flags&protocol.BITFLAG_SHIP_UPDATE_SIZE != 0 {
+		ship.Size = float64(update.Size)
+	}
+
+	if update.Flags&protocol.BITFLAG_SHIP_UPDATE_ROTATION != 0 {
+		ship.Rotation = protocol.DequantizeAngle12(update.Angle)
+	}
+
+	if update.Flags&protocol.BITFLAG_SHIP_UPDATE_HEALTH != 0 {
+		ship.Health = float64(update.Health)
+	}
+}
+
+func (w *World) parseIsland(reader *protocol.Reader, id uint64, isNew bool) {
+	if !isNew {
+		return
+	}
+
+	var create protocol.IslandCreate
+	if create.Read(reader) != nil {
+		return
+	}
+
+	w.Islands[id] = &Island{
+		ID:       id,
+		X:        float64(create.X),
+		Y:        float64(create.Y),
+		Size:     float64(create.Size),
+		Rotation: float64(create.Rotation),
+	}
+}
+
+// Copy of the bot's own ship, nil while it has none
+func (w *World) Own() *Ship {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if ship, ok := w.Ships[w.EntityID]; ok {
+		var copied Ship = *ship
+		return &copied
+	}
+
+	return nil
+}
+
+// Copy of the closest ship other than the bot's own, nil if none are in view
+func (w *World) Nearest() (nearest *Ship) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	var closest float64 = math.Inf(1)
+	for id, ship := range w.Ships {
+		if id == w.EntityID {
+			continue
+		}
+
+		if distance := math.Hypot(ship.X-w.X, ship.Y-w.Y); distance < closest {
+			var copied Ship = *ship
+			nearest, closest = &copied, distance
+		}
+	}
+
+	return
+}
+
+// The camera center, where the own ship is while the bot has one
+func (w *World) Center() *util.Vector2D {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return util.Vector(w.X, w.Y)
+}
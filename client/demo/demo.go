@@ -0,0 +1,239 @@
+// Demo files hold everything the server sent one client and when it arrived, so a session can be watched
+// again without a server. Little endian layout:
+//
+//	"DEMO", format version u16, protocol version u16, start unix nanoseconds i64,
+//	name and room (u16 length then UTF-8), ship u8,
+//	then records until the end of the file: microseconds since start uvarint, length uvarint, message
+package demo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	DEMO_MAGIC          string        = "DEMO"
+	DEMO_FORMAT_VERSION uint16        = 1
+	DEMO_FLUSH_INTERVAL time.Duration = time.Second // A crash loses at most this much of the recording
+	MAX_RECORD_SIZE     uint64        = 1 << 24
+)
+
+type (
+	// The join the recording client sent, the server's answer is the first record
+	Header struct {
+		Protocol   uint16
+		Start      time.Time
+		Name, Room string
+		ShipID     uint8
+	}
+
+	// One message as it came off the socket
+	Record struct {
+		At      time.Duration // Since Header.Start
+		Message []byte
+	}
+
+	Demo struct {
+		Header  Header
+		Records []Record
+	}
+
+	// Appends received messages to a demo file. Safe to call from the socket goroutine while the game runs.
+	Recorder struct {
+		mu        sync.Mutex
+		file      *os.File
+		writer    *bufio.Writer
+		start     time.Time
+		lastFlush time.Time
+		err       error
+	}
+)
+
+func writeString(w io.Writer, value string) error {
+	if len(value) > 0xFFFF {
+		return fmt.Errorf("string of %d bytes is too long", len(value))
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, uint16(len(value))); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, value)
+	return err
+}
+
+func readString(r *bytes.Reader) (value string, err error) {
+	var length uint16
+	if err = binary.Read(r, binary.LittleEndian, &length); err != nil {
+		return
+	}
+
+	var buffer []byte = make([]byte, length)
+	if _, err = io.ReadFull(r, buffer); err != nil {
+		return
+	}
+
+	return string(buffer), nil
+}
+
+// Creates the file and writes the header, the clock starts now
+func Create(path string, header Header) (r *Recorder, err error) {
+	var file *os.File
+	if file, err = os.Create(path); err != nil {
+		return
+	}
+
+	r = &Recorder{
+		file:   file,
+		writer: bufio.NewWriter(file),
+		start:  time.Now(),
+	}
+
+	r.lastFlush = r.start
+	header.Start = r.start
+
+	// Write errors stick to the bufio.Writer, so the flush reports any of them
+	var w *bufio.Writer = r.writer
+	w.WriteString(DEMO_MAGIC)
+	binary.Write(w, binary.LittleEndian, DEMO_FORMAT_VERSION)
+	binary.Write(w, binary.LittleEndian, header.Protocol)
+	binary.Write(w, binary.LittleEndian, header.Start.UnixNano())
+
+	if err = writeString(w, header.Name); err == nil {
+		err = writeString(w, header.Room)
+	}
+
+	w.WriteByte(header.ShipID)
+	if err == nil {
+		err = w.Flush()
+	}
+
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return
+}
+
+// Stops recording after the first write error, Close reports it
+func (r *Recorder) Record(message []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return
+	}
+
+	var now time.Time = time.Now()
+	var prefix []byte = binary.AppendUvarint(nil, uint64(now.Sub(r.start).Microseconds()))
+	prefix = binary.AppendUvarint(prefix, uint64(len(message)))
+
+	if _, r.err = r.writer.Write(prefix); r.err != nil {
+		return
+	}
+
+	if _, r.err = r.writer.Write(message); r.err != nil {
+		return
+	}
+
+	if now.Sub(r.lastFlush) >= DEMO_FLUSH_INTERVAL {
+		r.err = r.writer.Flush()
+		r.lastFlush = now
+	}
+}
+
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err == nil {
+		r.err = r.writer.Flush()
+	}
+
+	return errors.Join(r.err, r.file.Close())
+}
+
+// Reads a whole demo into memory. A record cut off at the end, as a crashed client leaves behind, is dropped.
+func Load(path string) (d *Demo, err error) {
+	var data []byte
+	if data, err = os.ReadFile(path); err != nil {
+		return
+	}
+
+	var (
+		r       *bytes.Reader = bytes.NewReader(data)
+		magic   []byte        = make([]byte, len(DEMO_MAGIC))
+		format  uint16
+		started int64
+	)
+
+	if _, err = io.ReadFull(r, magic); err != nil || string(magic) != DEMO_MAGIC {
+		return nil, fmt.Errorf("%s is not a demo file", path)
+	}
+
+	if err = binary.Read(r, binary.LittleEndian, &format); err != nil {
+		return nil, err
+	}
+
+	if format != DEMO_FORMAT_VERSION {
+		return nil, fmt.Errorf("demo format v%d is not supported, expected v%d", format, DEMO_FORMAT_VERSION)
+	}
+
+	d = &Demo{}
+	if err = binary.Read(r, binary.LittleEndian, &d.Header.Protocol); err == nil {
+		err = binary.Read(r, binary.LittleEndian, &started)
+	}
+
+	if err == nil {
+		d.Header.Name, err = readString(r)
+	}
+
+	if err == nil {
+		d.Header.Room, err = readString(r)
+	}
+
+	if err == nil {
+		d.Header.ShipID, err = r.ReadByte()
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("demo header: %w", err)
+	}
+
+	d.Header.Start = time.Unix(0, started)
+
+	for r.Len() > 0 {
+		at, err := binary.ReadUvarint(r)
+		if err != nil {
+			break
+		}
+
+		length, err := binary.ReadUvarint(r)
+		if err != nil || length > MAX_RECORD_SIZE || length > uint64(r.Len()) {
+			break
+		}
+
+		var message []byte = make([]byte, length)
+		r.Read(message)
+		d.Records = append(d.Records, Record{At: time.Duration(at) * time.Microsecond, Message: message})
+	}
+
+	return d, nil
+}
+
+// Time of the last record
+func (d *Demo) Duration() time.Duration {
+	if len(d.Records) == 0 {
+		return 0
+	}
+
+	return d.Records[len(d.Records)-1].At
+}
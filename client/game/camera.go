@@ -17,17 +17,25 @@ func newCamera() *PlayerCamera {
 		RealZoom:     4,
 		Width:        1000,
 		Height:       1000,
+		Snapshots:    NewSnapshotBuffer(),
 	}
 }
 
 func (c *PlayerCamera) Update() {
 	c.RealZoom = math.Max(math.Min(c.RealZoom, 5), .05)
-
-	c.Position.X = util.Lerp(c.Position.X, c.RealPosition.X, .1)
-	c.Position.Y = util.Lerp(c.Position.Y, c.RealPosition.Y, .1)
 	c.Zoom = util.Lerp(c.Zoom, c.RealZoom, .1)
 }
 
+// Moves the camera to where the server had it at the render tick
+func (c *PlayerCamera) Interpolate(tick float64) {
+	if snapshot, ok := c.Snapshots.Sample(tick); ok {
+		c.Position.X, c.Position.Y = snapshot.X, snapshot.Y
+		return
+	}
+
+	c.Position.X, c.Position.Y = c.RealPosition.X, c.RealPosition.Y
+}
+
 func (c *PlayerCamera) IsInView(position *util.Vector2D, radius float64) (inView bool) {
 	if radius*c.Zoom < MIN_DRAW_SIZE {
 		inView = false
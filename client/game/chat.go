@@ -0,0 +1,144 @@
+package game
+
+import (
+	"image/color"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/z46-dev/game-dev-project/shared/protocol"
+)
+
+const (
+	CHAT_HISTORY_LENGTH int = 50
+	CHAT_VISIBLE_LINES  int = 8
+	CHAT_FADE_TICKS     int = 60 * 10 // Messages disappear after this many frames unless the box is open
+	CHAT_LINE_HEIGHT    int = 16
+)
+
+var chatChannelPrefixes map[uint8]string = map[uint8]string{
+	protocol.CHAT_CHANNEL_ALL:     "[All]",
+	protocol.CHAT_CHANNEL_FACTION: "[Faction]",
+	protocol.CHAT_CHANNEL_WHISPER: "[Whisper]",
+	protocol.CHAT_CHANNEL_SYSTEM:  "",
+}
+
+func (g *Game) ParseChat(reader *protocol.Reader) {
+	var packet protocol.ChatReceive
+	if packet.Read(reader) != nil {
+		return
+	}
+
+	var message *ChatMessage = &ChatMessage{
+		Channel: packet.Channel,
+		Sender:  packet.Sender,
+		Message: packet.Message,
+	}
+
+	g.ChatMu.Lock()
+	message.ReceivedAt = g.LocalTime
+	g.Chat.Messages = append(g.Chat.Messages, message)
+	if len(g.Chat.Messages) > CHAT_HISTORY_LENGTH {
+		g.Chat.Messages = g.Chat.Messages[len(g.Chat.Messages)-CHAT_HISTORY_LENGTH:]
+	}
+	g.ChatMu.Unlock()
+}
+
+// Sends the typed line. "/f message" goes to the faction, "/w name message" whispers, anything else goes to all.
+func (g *Game) sendChat(line string) {
+	var (
+		channel uint8 = protocol.CHAT_CHANNEL_ALL
+		target  string
+	)
+
+	if rest, ok := strings.CutPrefix(line, "/f "); ok {
+		channel, line = protocol.CHAT_CHANNEL_FACTION, rest
+	} else if rest, ok := strings.CutPrefix(line, "/w "); ok {
+		var found bool
+		if target, line, found = strings.Cut(rest, " "); !found {
+			return
+		}
+
+		channel = protocol.CHAT_CHANNEL_WHISPER
+	}
+
+	if line = strings.TrimSpace(line); line == "" {
+		return
+	}
+
+	var w *protocol.Writer = g.NewWriter()
+	w.SetU8(protocol.PACKET_SERVERBOUND_CHAT)
+	(&protocol.ChatSend{Channel: channel, Target: target, Message: line}).Write(w)
+	g.Socket.Write(w.GetBytes())
+}
+
+// Enter opens and sends, Escape cancels. Reports whether the chat box has the keyboard.
+func (g *Game) updateChatControls() (typing bool) {
+	g.ChatMu.Lock()
+	defer g.ChatMu.Unlock()
+
+	if !g.Chat.Typing {
+		if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+			g.Chat.Typing = true
+			g.Chat.Input = g.Chat.Input[:0]
+			return true
+		}
+
+		return false
+	}
+
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		g.Chat.Typing = false
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		g.Chat.Typing = false
+		g.sendChat(string(g.Chat.Input))
+	case inpututil.IsKeyJustPressed(ebiten.KeyBackspace) || (inpututil.KeyPressDuration(ebiten.KeyBackspace) > 30 && inpututil.KeyPressDuration(ebiten.KeyBackspace)%3 == 0):
+		if len(g.Chat.Input) > 0 {
+			g.Chat.Input = g.Chat.Input[:len(g.Chat.Input)-1]
+		}
+	default:
+		g.Chat.Input = ebiten.AppendInputChars(g.Chat.Input)
+		if len(g.Chat.Input) > protocol.CHAT_MAX_LENGTH {
+			g.Chat.Input = g.Chat.Input[:protocol.CHAT_MAX_LENGTH]
+		}
+	}
+
+	return true
+}
+
+func (g *Game) drawChat(screen *ebiten.Image) {
+	g.ChatMu.Lock()
+	defer g.ChatMu.Unlock()
+
+	var (
+		visible []*ChatMessage
+		bottom  int = screen.Bounds().Dy()/2 + CHAT_VISIBLE_LINES*CHAT_LINE_HEIGHT/2
+	)
+
+	for i := len(g.Chat.Messages) - 1; i >= 0 && len(visible) < CHAT_VISIBLE_LINES; i-- {
+		if !g.Chat.Typing && g.LocalTime-g.Chat.Messages[i].ReceivedAt > CHAT_FADE_TICKS {
+			break
+		}
+
+		visible = append(visible, g.Chat.Messages[i])
+	}
+
+	if g.Chat.Typing {
+		vector.FillRect(screen, 8, float32(bottom+4), 420, float32(CHAT_LINE_HEIGHT+4), color.RGBA{A: 180}, false)
+		ebitenutil.DebugPrintAt(screen, "> "+string(g.Chat.Input)+"_", 12, bottom+6)
+	}
+
+	for i, message := range visible {
+		var line string = message.Message
+		if message.Channel != protocol.CHAT_CHANNEL_SYSTEM {
+			line = chatChannelPrefixes[message.Channel] + " " + message.Sender + ": " + line
+		} else {
+			line = message.Sender + " " + line
+		}
+
+		ebitenutil.DebugPrintAt(screen, line, 12, bottom-(i+1)*CHAT_LINE_HEIGHT)
+	}
+}
@@ -2,22 +2,35 @@ package game
 
 import (
 	"fmt"
+	"math"
 	"sort"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/z46-dev/game-dev-project/assets"
 	"github.com/z46-dev/game-dev-project/client/shaders"
 	"github.com/z46-dev/game-dev-project/shared"
 	"github.com/z46-dev/game-dev-project/shared/definitions"
 	"github.com/z46-dev/game-dev-project/shared/protocol"
 	"github.com/z46-dev/game-dev-project/util"
+	"golang.org/x/image/colornames"
 )
 
 func NewGame() (g *Game) {
 	g = &Game{
 		Camera:        newCamera(),
 		Ships:         make(map[uint64]*ClientShip),
+		Islands:       make(map[uint64]*ClientIsland),
+		Chat:          &ChatState{},
+		Leaderboard:   &LeaderboardState{},
 		MousePosition: util.Vector(0, 0),
+		Protocol:      protocol.PROTOCOL_VERSION,
+		Clock:         NewServerClock(),
+		Prediction:    NewPrediction(),
+
+		InterpolationDelay: DEFAULT_INTERP_DELAY,
 	}
 
 	return
@@ -27,53 +40,147 @@ func (g *Game) Update() (err error) {
 	g.LocalTime++
 	var width, height int = ebiten.WindowSize()
 	g.Camera.Width, g.Camera.Height = float64(width), float64(height)
+
+	if g.Playback != nil {
+		g.updatePlayback()
+	}
+
 	g.Camera.Update()
 
+	// The chat box swallows the keyboard while it is open
+	var typing bool = g.Socket != nil && g.updateChatControls()
+
+	if !typing && inpututil.IsKeyJustPressed(ebiten.KeyM) {
+		g.ShowTacticalMap = !g.ShowTacticalMap
+	}
+
+	if !typing && g.ServerAddress != "" {
+		g.updateLeaderboardControls()
+	}
+
 	if g.Socket != nil {
 		var flags uint8
-		if ebiten.IsKeyPressed(ebiten.KeyArrowLeft) || ebiten.IsKeyPressed(ebiten.KeyA) {
-			flags |= protocol.BITFLAG_INPUT_LEFT
-		}
+		if !typing {
+			if ebiten.IsKeyPressed(ebiten.KeyArrowLeft) || ebiten.IsKeyPressed(ebiten.KeyA) {
+				flags |= protocol.BITFLAG_INPUT_LEFT
+			}
 
-		if ebiten.IsKeyPressed(ebiten.KeyArrowRight) || ebiten.IsKeyPressed(ebiten.KeyD) {
-			flags |= protocol.BITFLAG_INPUT_RIGHT
-		}
+			if ebiten.IsKeyPressed(ebiten.KeyArrowRight) || ebiten.IsKeyPressed(ebiten.KeyD) {
+				flags |= protocol.BITFLAG_INPUT_RIGHT
+			}
 
-		if ebiten.IsKeyPressed(ebiten.KeyArrowUp) || ebiten.IsKeyPressed(ebiten.KeyW) {
-			flags |= protocol.BITFLAG_INPUT_UP
-		}
+			if ebiten.IsKeyPressed(ebiten.KeyArrowUp) || ebiten.IsKeyPressed(ebiten.KeyW) {
+				flags |= protocol.BITFLAG_INPUT_UP
+			}
 
-		if ebiten.IsKeyPressed(ebiten.KeyArrowDown) || ebiten.IsKeyPressed(ebiten.KeyS) {
-			flags |= protocol.BITFLAG_INPUT_DOWN
+			if ebiten.IsKeyPressed(ebiten.KeyArrowDown) || ebiten.IsKeyPressed(ebiten.KeyS) {
+				flags |= protocol.BITFLAG_INPUT_DOWN
+			}
+
+			if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+				flags |= protocol.BITFLAG_INPUT_LMB
+			}
 		}
 
 		var newMouse *util.Vector2D = g.Camera.RealMousePosition()
 		if newMouse.X != g.MousePosition.X || newMouse.Y != g.MousePosition.Y {
-			flags |= protocol.BITFLAG_MOUSE_MOVE
 			g.MousePosition = newMouse
+			g.mouseMoved = true
 		}
 
-		if flags != g.lastInputFlags || (flags&protocol.BITFLAG_MOUSE_MOVE != 0) {
-			var w *protocol.Writer = new(protocol.Writer)
-			w.SetU8(protocol.PACKET_SERVERBOUND_INPUT)
-			w.SetU8(flags)
+		if g.mouseMoved {
+			flags |= protocol.BITFLAG_MOUSE_MOVE
+		}
 
-			if flags&protocol.BITFLAG_MOUSE_MOVE != 0 {
-				w.SetF32(float32(g.MousePosition.X))
-				w.SetF32(float32(g.MousePosition.Y))
+		// A predicted ship sends every tick, otherwise only changes are sent
+		if g.predicting() {
+			if g.updatePrediction(flags) {
+				g.mouseMoved = false
 			}
+		} else if flags != g.lastInputFlags || g.mouseMoved {
+			var w *protocol.Writer = g.NewWriter()
+			w.SetU8(protocol.PACKET_SERVERBOUND_INPUT)
+			(&protocol.Input{
+				Flags:    flags,
+				MouseX:   float32(g.MousePosition.X),
+				MouseY:   float32(g.MousePosition.Y),
+				Sequence: g.nextSequence(),
+				ViewTick: g.viewTick(),
+			}).Write(w)
 
 			g.Socket.Write(w.GetBytes())
 			g.lastInputFlags = flags
+			g.mouseMoved = false
+		}
+
+		if !typing {
+			if g.Spectator != nil {
+				g.updateSpectatorControls()
+			} else {
+				g.updateConsumableControls()
+			}
 		}
 	}
 
 	return
 }
 
+// Tab/E and Q cycle ships, F toggles the free camera, G toggles all factions, the wheel zooms
+func (g *Game) updateSpectatorControls() {
+	var actions []uint8
+	if inpututil.IsKeyJustPressed(ebiten.KeyTab) || inpututil.IsKeyJustPressed(ebiten.KeyE) {
+		actions = append(actions, protocol.SPECTATE_ACTION_NEXT)
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyQ) {
+		actions = append(actions, protocol.SPECTATE_ACTION_PREVIOUS)
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyF) {
+		actions = append(actions, protocol.SPECTATE_ACTION_TOGGLE_FREE_CAM)
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyG) {
+		actions = append(actions, protocol.SPECTATE_ACTION_TOGGLE_ALL_FACTIONS)
+	}
+
+	for _, action := range actions {
+		var w *protocol.Writer = g.NewWriter()
+		w.SetU8(protocol.PACKET_SERVERBOUND_SPECTATE)
+		(&protocol.SpectateAction{Action: action}).Write(w)
+		g.Socket.Write(w.GetBytes())
+	}
+
+	if _, wheel := ebiten.Wheel(); wheel != 0 {
+		g.Spectator.FOV *= math.Pow(0.9, wheel)
+
+		var w *protocol.Writer = g.NewWriter()
+		w.SetU8(protocol.PACKET_SERVERBOUND_SPECTATE)
+		(&protocol.SpectateAction{Action: protocol.SPECTATE_ACTION_SET_FOV, FOV: float32(g.Spectator.FOV)}).Write(w)
+		g.Socket.Write(w.GetBytes())
+	}
+}
+
 func (g *Game) Draw(screen *ebiten.Image) {
 	var bounds = screen.Bounds()
 
+	// Sampled per frame rather than per Update so motion stays smooth above 60 Hz
+	g.updateRenderTick()
+	g.Camera.Interpolate(g.renderTick)
+
+	// The own ship is drawn where it is predicted to be now, and the camera stays on it
+	g.ownSnapshot = nil
+	if g.predicting() {
+		if snapshot, ok := g.Prediction.Sample(time.Now(), g.tickInterval()); ok {
+			g.ownSnapshot = &snapshot
+			g.Camera.Position.X, g.Camera.Position.Y = snapshot.X, snapshot.Y
+		}
+	}
+
+	if g.Playback != nil && g.Playback.FreeCam {
+		g.Camera.Position.X, g.Camera.Position.Y = g.Playback.Camera.X, g.Playback.Camera.Y
+	}
+
 	screen.DrawRectShader(bounds.Dx(), bounds.Dy(), shaders.BackgroundShader, &ebiten.DrawRectShaderOptions{
 		GeoM: ebiten.GeoM{},
 		Uniforms: map[string]any{
@@ -83,6 +190,14 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		},
 	})
 
+	g.IslandsMu.RLock()
+	for _, island := range g.Islands {
+		island.Draw(g, screen)
+	}
+	g.IslandsMu.RUnlock()
+
+	g.drawObjectiveZones(screen)
+
 	g.ShipsMu.RLock()
 	var ships []*ClientShip = make([]*ClientShip, 0, len(g.Ships))
 	for _, ship := range g.Ships {
@@ -98,6 +213,27 @@ func (g *Game) Draw(screen *ebiten.Image) {
 	for _, ship := range ships {
 		ship.Draw(g, screen)
 	}
+
+	g.drawHUD(screen)
+	g.drawMap(screen)
+	g.drawChat(screen)
+	g.drawLeaderboard(screen)
+	g.drawDisconnect(screen)
+	g.drawPlayback(screen)
+
+	if g.Spectator != nil && g.Playback == nil {
+		var mode string = "following"
+		if g.Spectator.FreeCam {
+			mode = "free camera"
+		}
+
+		var scope string = "own faction"
+		if g.Spectator.AllFactions {
+			scope = "all factions"
+		}
+
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("SPECTATING (%s, %s)\n[Tab/Q] cycle  [F] free camera  [G] faction scope", mode, scope), 8, 8)
+	}
 }
 
 func (g *Game) Layout(_, _ int) (w, h int) {
@@ -106,12 +242,37 @@ func (g *Game) Layout(_, _ int) (w, h int) {
 }
 
 func (g *Game) ParseViewUpdate(reader *protocol.Reader) {
-	g.ServerTime = int(reader.GetU32())
-	g.Camera.RealPosition.X = float64(reader.GetF32())
-	g.Camera.RealPosition.Y = float64(reader.GetF32())
-	g.Camera.RealZoom = g.Camera.Width / float64(reader.GetF32())
+	var header protocol.ViewHeader
+	if header.Read(reader) != nil {
+		return
+	}
+
+	g.ServerTime = int(header.Time)
+	g.Camera.RealPosition.X = float64(header.X)
+	g.Camera.RealPosition.Y = float64(header.Y)
+	g.Camera.RealZoom = g.Camera.Width / float64(header.FOV)
+
+	g.PlayerID = header.EntityID
+
+	if g.Protocol >= protocol.PROTOCOL_PREDICTION_VERSION {
+		if header.EntityID != 0 {
+			g.Prediction.Reconcile(&header)
+		} else {
+			g.Prediction.Reset()
+		}
+	}
+
+	if header.Spectating == 0 {
+		g.Spectator = nil
+	} else {
+		if g.Spectator == nil {
+			g.Spectator = &SpectatorState{FOV: g.Camera.Width / g.Camera.RealZoom}
+		}
 
-	reader.GetU64()
+		g.Spectator.TargetID = header.SpectateTarget
+		g.Spectator.FreeCam = header.SpectateFlags&protocol.BITFLAG_SPECTATE_FREE_CAM != 0
+		g.Spectator.AllFactions = header.SpectateFlags&protocol.BITFLAG_SPECTATE_ALL_FACTIONS != 0
+	}
 
 	// Entities in View
 	for {
@@ -121,7 +282,7 @@ func (g *Game) ParseViewUpdate(reader *protocol.Reader) {
 			isNew      bool
 		)
 
-		if id = reader.GetU64(); id == 0 {
+		if id = reader.GetVarU64(); id == 0 {
 			break
 		}
 
@@ -131,6 +292,8 @@ func (g *Game) ParseViewUpdate(reader *protocol.Reader) {
 		switch entityType {
 		case protocol.ENTITY_TYPE_SHIP:
 			g.ParseIncomingShip(reader, id, isNew)
+		case protocol.ENTITY_TYPE_ISLAND:
+			g.ParseIncomingIsland(reader, id, isNew)
 		default:
 			fmt.Printf("Unknown entity type: %d\n", entityType)
 		}
@@ -138,7 +301,7 @@ func (g *Game) ParseViewUpdate(reader *protocol.Reader) {
 
 	// Deletes
 	for {
-		var id uint64 = reader.GetU64()
+		var id uint64 = reader.GetVarU64()
 		if id == 0 {
 			break
 		}
@@ -149,36 +312,58 @@ func (g *Game) ParseViewUpdate(reader *protocol.Reader) {
 			g.ShipsMu.Lock()
 			delete(g.Ships, id)
 			g.ShipsMu.Unlock()
+		case protocol.ENTITY_TYPE_ISLAND:
+			g.IslandsMu.Lock()
+			delete(g.Islands, id)
+			g.IslandsMu.Unlock()
 		}
 	}
+
+	// Ships the server held back to stay within its budget, they did not necessarily stop moving
+	var deferred map[uint64]bool = make(map[uint64]bool)
+	if g.Protocol >= protocol.PROTOCOL_INTEREST_VERSION {
+		for id := reader.GetVarU64(); id != 0; id = reader.GetVarU64() {
+			deferred[id] = true
+		}
+	}
+
+	if reader.Err() == nil {
+		g.recordSnapshots(deferred)
+	}
 }
 
 func (g *Game) ParseIncomingShip(reader *protocol.Reader, id uint64, isNew bool) {
 	if isNew {
+		var create protocol.ShipCreate
+		if create.Read(reader) != nil {
+			return
+		}
+
 		var ship *ClientShip = &ClientShip{
-			ID:       id,
-			Position: util.Vector(float64(reader.GetF32()), float64(reader.GetF32())),
-			Size:     float64(reader.GetF32()),
-			Rotation: float64(reader.GetF32()),
-			Name:     reader.GetStringUTF8(),
+			ID:          id,
+			Position:    util.Vector(float64(create.X), float64(create.Y)),
+			Size:        float64(create.Size),
+			Rotation:    float64(create.Rotation),
+			Name:        create.Name,
+			HealthRatio: float64(create.Health),
+			Snapshots:   NewSnapshotBuffer(),
+		}
+
+		if g.Protocol >= protocol.PROTOCOL_QUANTIZED_VERSION {
+			ship.Position = g.dequantizePosition(create.OffsetX, create.OffsetY)
+			ship.Rotation = protocol.DequantizeAngle12(create.Angle)
 		}
 
 		ship.RealPosition = ship.Position.Copy()
 		ship.RealSize = ship.Size
 		ship.RealRotation = ship.Rotation
 
-		var points []*util.Vector2D = make([]*util.Vector2D, 0, reader.GetU16())
-		for range cap(points) {
-			points = append(points, util.Vector(float64(reader.GetF32()), float64(reader.GetF32())))
-		}
-
-		ship.Definition = definitions.MustGetByKey(definitions.ShipConfigs, definitions.ShipID(reader.GetU8()))
+		ship.Definition = definitions.MustGetByKey(definitions.ShipConfigs, definitions.ShipID(create.ShipID))
 		if ship.Definition.AssetName != "" {
 			ship.asset = assets.MustGet("assets/ships/" + ship.Definition.AssetName)
 		} else {
 			ship.asset = shared.CreateAssetForPolygon(ship.Definition.HullPath, ship.Size)
 		}
-		ship.HealthRatio = float64(reader.GetF32())
 
 		g.ShipsMu.Lock()
 		g.Ships[id] = ship
@@ -193,23 +378,66 @@ func (g *Game) ParseIncomingShip(reader *protocol.Reader, id uint64, isNew bool)
 			return
 		}
 
-		var flags uint8 = reader.GetU8()
+		var update protocol.ShipUpdate
+		if update.Read(reader) != nil {
+			return
+		}
+
+		var quantized bool = g.Protocol >= protocol.PROTOCOL_QUANTIZED_VERSION
 
-		if flags&(1<<0) != 0 {
-			ship.RealPosition.X = float64(reader.GetF32())
-			ship.RealPosition.Y = float64(reader.GetF32())
+		if update.Flags&protocol.BITFLAG_SHIP_UPDATE_POSITION != 0 {
+			if quantized {
+				ship.RealPosition = g.dequantizePosition(update.OffsetX, update.OffsetY)
+			} else {
+				ship.RealPosition.X = float64(update.X)
+				ship.RealPosition.Y = float64(update.Y)
+			}
 		}
 
-		if flags&(1<<1) != 0 {
-			ship.RealSize = float64(reader.GetF32())
+		if update.Flags&protocol.BITFLAG_SHIP_UPDATE_SIZE != 0 {
+			ship.RealSize = float64(update.Size)
 		}
 
-		if flags&(1<<2) != 0 {
-			ship.RealRotation = float64(reader.GetF32())
+		if update.Flags&protocol.BITFLAG_SHIP_UPDATE_ROTATION != 0 {
+			if quantized {
+				ship.RealRotation = protocol.DequantizeAngle12(update.Angle)
+			} else {
+				ship.RealRotation = float64(update.Rotation)
+			}
 		}
 
-		if flags&(1<<3) != 0 {
-			ship.HealthRatio = float64(reader.GetF32())
+		if update.Flags&protocol.BITFLAG_SHIP_UPDATE_HEALTH != 0 {
+			ship.HealthRatio = float64(update.Health)
 		}
 	}
-}
\ No newline at end of file
+}
+
+// Islands never change, so only the creation message carries a payload
+func (g *Game) ParseIncomingIsland(reader *protocol.Reader, id uint64, isNew bool) {
+	if !isNew {
+		return
+	}
+
+	var create protocol.IslandCreate
+	if create.Read(reader) != nil {
+		return
+	}
+
+	var island *ClientIsland = &ClientIsland{
+		ID:       id,
+		Position: util.Vector(float64(create.X), float64(create.Y)),
+		Size:     float64(create.Size),
+		Rotation: float64(create.Rotation),
+	}
+
+	var points []*util.Vector2D = make([]*util.Vector2D, len(create.Hull))
+	for i, p := range create.Hull {
+		points[i] = util.Vector(float64(p.X), float64(p.Y))
+	}
+
+	island.asset = shared.CreateShipAsset(points, island.Size, colornames.Darkolivegreen, colornames.Khaki)
+
+	g.IslandsMu.Lock()
+	g.Islands[id] = island
+	g.IslandsMu.Unlock()
+}
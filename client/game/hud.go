@@ -0,0 +1,181 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/z46-dev/game-dev-project/shared/definitions"
+	"github.com/z46-dev/game-dev-project/shared/protocol"
+	"golang.org/x/image/colornames"
+)
+
+var consumableKeys []ebiten.Key = []ebiten.Key{ebiten.KeyDigit1, ebiten.KeyDigit2, ebiten.KeyDigit3, ebiten.KeyDigit4}
+
+func (g *Game) ParseGUIUpdate(reader *protocol.Reader) {
+	var update protocol.GUIUpdate
+	if update.Read(reader) != nil {
+		return
+	}
+
+	var hud *HUDState = &HUDState{
+		HasBody:     update.HasBody == 1,
+		Score:       int(update.Score),
+		MatchTime:   int(update.Elapsed),
+		MatchLength: int(update.MatchLength),
+	}
+
+	if hud.HasBody {
+		hud.Ship, _ = definitions.GetByKey(definitions.ShipConfigs, definitions.ShipID(update.ShipID))
+		hud.Health = float64(update.Health)
+		hud.MaxHealth = float64(update.MaxHealth)
+		hud.Speed = float64(update.Speed)
+		hud.MaxSpeed = float64(update.MaxSpeed)
+
+		hud.Squadrons = make([]*HUDSquadron, len(update.Squadrons))
+		for i, s := range update.Squadrons {
+			var squadron *HUDSquadron = &HUDSquadron{
+				Planes:      int(s.Planes),
+				HangarSize:  int(s.HangarSize),
+				LaunchTimer: float64(s.LaunchTimer) / 10,
+				RegenTimer:  float64(s.RegenTimer) / 10,
+			}
+
+			squadron.Plane, _ = definitions.GetByKey(definitions.PlaneConfigs, definitions.PlaneID(s.PlaneID))
+			hud.Squadrons[i] = squadron
+		}
+
+		hud.Consumables = make([]*HUDConsumable, len(update.Consumables))
+		for i, c := range update.Consumables {
+			var consumable *HUDConsumable = &HUDConsumable{
+				Charges:  int(c.Charges),
+				Cooldown: float64(c.Cooldown) / 10,
+				Active:   float64(c.Active) / 10,
+			}
+
+			if c.Charges == 0xFF {
+				consumable.Charges = -1
+			}
+
+			consumable.Definition, _ = definitions.GetByKey(definitions.ConsumableConfigs, definitions.ConsumableID(c.ConsumableID))
+			hud.Consumables[i] = consumable
+		}
+	}
+
+	g.HUDMu.Lock()
+	g.HUD = hud
+	g.HUDMu.Unlock()
+}
+
+func (g *Game) updateConsumableControls() {
+	for i, key := range consumableKeys {
+		if !inpututil.IsKeyJustPressed(key) {
+			continue
+		}
+
+		var w *protocol.Writer = g.NewWriter()
+		w.SetU8(protocol.PACKET_SERVERBOUND_CONSUMABLE)
+		(&protocol.ConsumableActivate{Slot: uint8(i)}).Write(w)
+		g.Socket.Write(w.GetBytes())
+	}
+}
+
+func formatClock(seconds int) string {
+	return fmt.Sprintf("%02d:%02d", seconds/60, seconds%60)
+}
+
+func (g *Game) drawHUD(screen *ebiten.Image) {
+	g.HUDMu.RLock()
+	var hud *HUDState = g.HUD
+	g.HUDMu.RUnlock()
+
+	if hud == nil {
+		return
+	}
+
+	var bounds = screen.Bounds()
+	var width, height int = bounds.Dx(), bounds.Dy()
+
+	// Match timer and score
+	var clock string = formatClock(hud.MatchTime)
+	if hud.MatchLength > 0 {
+		clock = formatClock(max(0, hud.MatchLength-hud.MatchTime))
+	}
+
+	ebitenutil.DebugPrintAt(screen, clock, width/2-len(clock)*3, 8)
+	var score string = fmt.Sprintf("Score: %d", hud.Score)
+	ebitenutil.DebugPrintAt(screen, score, width-len(score)*6-12, 8)
+
+	if !hud.HasBody {
+		return
+	}
+
+	// Own ship panel
+	const panelWidth, lineHeight = 260, 16
+	var lines int = 3 + len(hud.Squadrons) + len(hud.Consumables)
+	var x, y float32 = 12, float32(height - 12 - lines*lineHeight - 12)
+
+	vector.FillRect(screen, x, y, panelWidth, float32(lines*lineHeight+12), mapBackgroundColor, false)
+
+	var name string = "Unknown"
+	if hud.Ship != nil {
+		name = hud.Ship.Name
+	}
+
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("%s  %.0f / %.0f", name, hud.Health, hud.MaxHealth), int(x)+6, int(y)+4)
+
+	var barY float32 = y + lineHeight + 6
+	var ratio float32 = 0
+	if hud.MaxHealth > 0 {
+		ratio = float32(hud.Health / hud.MaxHealth)
+	}
+
+	vector.FillRect(screen, x+6, barY, panelWidth-12, 6, colornames.Black, false)
+	vector.FillRect(screen, x+6, barY, (panelWidth-12)*ratio, 6, colornames.Limegreen, false)
+
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Speed %.0f / %.0f", hud.Speed, hud.MaxSpeed), int(x)+6, int(y)+lineHeight*2)
+
+	var line int = 3
+	for _, squadron := range hud.Squadrons {
+		var planeName string = "Squadron"
+		if squadron.Plane != nil {
+			planeName = squadron.Plane.Name
+		}
+
+		var status string = "ready"
+		if squadron.LaunchTimer > 0 {
+			status = fmt.Sprintf("launch %.1fs", squadron.LaunchTimer)
+		} else if squadron.RegenTimer > 0 {
+			status = fmt.Sprintf("+1 in %.0fs", squadron.RegenTimer)
+		}
+
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("%d/%d %s (%s)", squadron.Planes, squadron.HangarSize, planeName, status), int(x)+6, int(y)+lineHeight*line)
+		line++
+	}
+
+	for i, consumable := range hud.Consumables {
+		var consumableName string = "Consumable"
+		if consumable.Definition != nil {
+			consumableName = consumable.Definition.Name
+		}
+
+		var status string = "ready"
+		if consumable.Active > 0 {
+			status = fmt.Sprintf("active %.0fs", consumable.Active)
+		} else if consumable.Cooldown > 0 {
+			status = fmt.Sprintf("%.0fs", consumable.Cooldown)
+		} else if consumable.Charges == 0 {
+			status = "empty"
+		}
+
+		var charges string = "inf"
+		if consumable.Charges >= 0 {
+			charges = fmt.Sprint(consumable.Charges)
+		}
+
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("[%d] %s x%s (%s)", i+1, consumableName, charges, status), int(x)+6, int(y)+lineHeight*line)
+		line++
+	}
+}
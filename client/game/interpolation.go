@@ -0,0 +1,171 @@
+package game
+
+import (
+	"math"
+	"time"
+
+	"github.com/z46-dev/game-dev-project/util"
+)
+
+const (
+	SNAPSHOT_BUFFER_SIZE     int           = 32                     // A second of history at 30 TPS
+	MAX_EXTRAPOLATION_TICKS  float64       = 6                      // How far past the newest snapshot entities keep moving when packets are late
+	CLOCK_SMOOTHING          float64       = 0.05                   // Fraction of the arrival jitter corrected per view update
+	CLOCK_RESYNC_TICKS       float64       = 10                     // Drift beyond this snaps the clock instead of easing it
+	DEFAULT_INTERP_DELAY     time.Duration = 100 * time.Millisecond // Three ticks at 30 TPS, rides out a lost or late update
+	DEFAULT_CLIENT_TICK_RATE float64       = 30
+)
+
+func NewSnapshotBuffer() *SnapshotBuffer {
+	return &SnapshotBuffer{snapshots: make([]Snapshot, 0, SNAPSHOT_BUFFER_SIZE)}
+}
+
+func (b *SnapshotBuffer) Push(s Snapshot) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if n := len(b.snapshots); n > 0 {
+		switch last := b.snapshots[n-1]; {
+		case s.Tick == last.Tick:
+			b.snapshots[n-1] = s
+			return
+		case s.Tick < last.Tick:
+			// The server restarted or the clock wrapped, old history is meaningless
+			b.snapshots = b.snapshots[:0]
+		}
+	}
+
+	if len(b.snapshots) == SNAPSHOT_BUFFER_SIZE {
+		b.snapshots = append(b.snapshots[:0], b.snapshots[1:]...)
+	}
+
+	b.snapshots = append(b.snapshots, s)
+}
+
+func lerpSnapshot(a, b Snapshot, t float64) Snapshot {
+	return Snapshot{
+		X:        util.Lerp(a.X, b.X, t),
+		Y:        util.Lerp(a.Y, b.Y, t),
+		Size:     util.Lerp(a.Size, b.Size, t),
+		Rotation: a.Rotation + util.AngleDifference(b.Rotation, a.Rotation)*t,
+	}
+}
+
+// Interpolates between the snapshots either side of tick, or extrapolates from the newest two for a
+// few ticks when nothing newer has arrived yet
+func (b *SnapshotBuffer) Sample(tick float64) (s Snapshot, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var n int = len(b.snapshots)
+	if n == 0 {
+		return
+	}
+
+	if first := b.snapshots[0]; tick <= float64(first.Tick) {
+		return first, true
+	}
+
+	for i := 1; i < n; i++ {
+		var previous, next Snapshot = b.snapshots[i-1], b.snapshots[i]
+		if tick <= float64(next.Tick) {
+			return lerpSnapshot(previous, next, (tick-float64(previous.Tick))/float64(next.Tick-previous.Tick)), true
+		}
+	}
+
+	var last Snapshot = b.snapshots[n-1]
+	if n == 1 {
+		return last, true
+	}
+
+	var previous Snapshot = b.snapshots[n-2]
+	var ahead float64 = min(tick-float64(last.Tick), MAX_EXTRAPOLATION_TICKS)
+	return lerpSnapshot(previous, last, 1+ahead/float64(last.Tick-previous.Tick)), true
+}
+
+func NewServerClock() *ServerClock {
+	return &ServerClock{TickRate: DEFAULT_CLIENT_TICK_RATE}
+}
+
+func (c *ServerClock) localTicks(now time.Time) float64 {
+	return float64(now.UnixNano()) / float64(time.Second) * c.TickRate
+}
+
+// Starts over, the offset is in ticks of the old rate
+func (c *ServerClock) SetTickRate(tickRate float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.TickRate = tickRate
+	c.synced = false
+}
+
+// Called with the tick of every view update as it arrives
+func (c *ServerClock) Observe(tick int, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var sample float64 = float64(tick) - c.localTicks(now)
+	if !c.synced || math.Abs(sample-c.offset) > CLOCK_RESYNC_TICKS {
+		c.offset = sample
+		c.synced = true
+		return
+	}
+
+	c.offset += (sample - c.offset) * CLOCK_SMOOTHING
+}
+
+func (c *ServerClock) Now(now time.Time) (tick float64, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.localTicks(now) + c.offset, c.synced
+}
+
+func (s *ClientShip) snapshot(tick int) Snapshot {
+	return Snapshot{
+		Tick:     tick,
+		X:        s.RealPosition.X,
+		Y:        s.RealPosition.Y,
+		Size:     s.RealSize,
+		Rotation: s.RealRotation,
+	}
+}
+
+// Records the state every entity is in as of this view update. Ships left out of a quantized update
+// did not change, so they get a snapshot too, unless the server said it deferred them. Those are
+// interpolated across the gap (or extrapolated) until their next update arrives.
+func (g *Game) recordSnapshots(deferred map[uint64]bool) {
+	var now time.Time = g.now()
+	g.Clock.Observe(g.ServerTime, now)
+
+	g.Camera.Snapshots.Push(Snapshot{Tick: g.ServerTime, X: g.Camera.RealPosition.X, Y: g.Camera.RealPosition.Y})
+
+	g.ShipsMu.RLock()
+	for _, ship := range g.Ships {
+		if !deferred[ship.ID] {
+			ship.Snapshots.Push(ship.snapshot(g.ServerTime))
+		}
+	}
+	g.ShipsMu.RUnlock()
+}
+
+// Picks the tick this frame is drawn at, InterpolationDelay behind the estimated server time
+func (g *Game) updateRenderTick() {
+	now, ok := g.Clock.Now(g.now())
+	if !ok {
+		g.renderTick = math.Inf(-1)
+		return
+	}
+
+	g.renderTick = now - g.InterpolationDelay.Seconds()*g.Clock.TickRate
+}
+
+// The server tick on screen, sent with inputs so aimed shots are checked against what was drawn
+func (g *Game) viewTick() uint32 {
+	if math.IsInf(g.renderTick, 0) || g.renderTick <= 0 {
+		return 0
+	}
+
+	return uint32(math.Round(g.renderTick))
+}
@@ -0,0 +1,146 @@
+package game
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/z46-dev/game-dev-project/client/web"
+)
+
+const LEADERBOARD_REFRESH time.Duration = 30 * time.Second // Matches the server's cache
+
+type leaderboardChoice struct {
+	Key, Label string
+}
+
+var (
+	leaderboardMetrics []leaderboardChoice = []leaderboardChoice{
+		{"score", "Score"},
+		{"kills", "Kills"},
+		{"damage", "Damage"},
+		{"captures", "Captures"},
+		{"planes_shot_down", "Planes shot down"},
+		{"wins", "Wins"},
+		{"time_alive", "Time alive"},
+	}
+
+	leaderboardWindows []leaderboardChoice = []leaderboardChoice{
+		{"daily", "Last 24 hours"},
+		{"weekly", "Last 7 days"},
+		{"all", "All time"},
+	}
+)
+
+// L opens and closes the board, [ and ] change the metric, , and . the window
+func (g *Game) updateLeaderboardControls() {
+	g.LeaderboardMu.Lock()
+	defer g.LeaderboardMu.Unlock()
+
+	var board *LeaderboardState = g.Leaderboard
+	if inpututil.IsKeyJustPressed(ebiten.KeyL) {
+		board.Open = !board.Open
+	}
+
+	if !board.Open {
+		return
+	}
+
+	var metric, window int = board.Metric, board.Window
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyBracketLeft):
+		metric = (metric + len(leaderboardMetrics) - 1) % len(leaderboardMetrics)
+	case inpututil.IsKeyJustPressed(ebiten.KeyBracketRight):
+		metric = (metric + 1) % len(leaderboardMetrics)
+	case inpututil.IsKeyJustPressed(ebiten.KeyComma):
+		window = (window + len(leaderboardWindows) - 1) % len(leaderboardWindows)
+	case inpututil.IsKeyJustPressed(ebiten.KeyPeriod):
+		window = (window + 1) % len(leaderboardWindows)
+	}
+
+	if metric != board.Metric || window != board.Window {
+		board.Metric, board.Window = metric, window
+		board.Board, board.Error, board.fetchedAt = nil, "", time.Time{}
+	}
+
+	if !board.loading && time.Since(board.fetchedAt) > LEADERBOARD_REFRESH {
+		board.loading = true
+		go g.fetchLeaderboard(metric, window)
+	}
+}
+
+// Runs in the background, the answer is dropped if the player switched boards in the meantime
+func (g *Game) fetchLeaderboard(metric, window int) {
+	result, err := web.GetLeaderboard(g.ServerAddress, leaderboardMetrics[metric].Key, leaderboardWindows[window].Key)
+
+	g.LeaderboardMu.Lock()
+	defer g.LeaderboardMu.Unlock()
+
+	var board *LeaderboardState = g.Leaderboard
+	board.loading, board.fetchedAt = false, time.Now()
+	if metric != board.Metric || window != board.Window {
+		board.fetchedAt = time.Time{}
+		return
+	}
+
+	if err != nil {
+		board.Error = err.Error()
+		return
+	}
+
+	board.Board, board.Error = &result, ""
+}
+
+func formatLeaderboardValue(metric string, value float64) string {
+	switch metric {
+	case "time_alive":
+		var seconds int = int(value)
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	case "damage":
+		return fmt.Sprintf("%.0f", value)
+	default:
+		return fmt.Sprintf("%d", int64(value))
+	}
+}
+
+func (g *Game) drawLeaderboard(screen *ebiten.Image) {
+	g.LeaderboardMu.Lock()
+	defer g.LeaderboardMu.Unlock()
+
+	var board *LeaderboardState = g.Leaderboard
+	if !board.Open {
+		return
+	}
+
+	const panelWidth, lineHeight, rows = 360, 16, 10
+	var (
+		bounds      = screen.Bounds()
+		x, y        = (bounds.Dx() - panelWidth) / 2, bounds.Dy()/2 - (rows+4)*lineHeight/2
+		metric      = leaderboardMetrics[board.Metric]
+		window      = leaderboardWindows[board.Window]
+		panelHeight = (rows+4)*lineHeight + 12
+	)
+
+	vector.FillRect(screen, float32(x), float32(y), panelWidth, float32(panelHeight), mapBackgroundColor, false)
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Leaderboard: %s, %s", metric.Label, window.Label), x+8, y+6)
+
+	var line int = 2
+	switch {
+	case board.Error != "":
+		ebitenutil.DebugPrintAt(screen, board.Error, x+8, y+6+line*lineHeight)
+	case board.Board == nil:
+		ebitenutil.DebugPrintAt(screen, "Loading...", x+8, y+6+line*lineHeight)
+	case len(board.Board.Entries) == 0:
+		ebitenutil.DebugPrintAt(screen, "Nobody has played in this window yet", x+8, y+6+line*lineHeight)
+	default:
+		for _, entry := range board.Board.Entries[:min(len(board.Board.Entries), rows)] {
+			ebitenutil.DebugPrintAt(screen, fmt.Sprintf("%2d. %-24s %s", entry.Rank, entry.Name, formatLeaderboardValue(metric.Key, entry.Value)), x+8, y+6+line*lineHeight)
+			line++
+		}
+	}
+
+	ebitenutil.DebugPrintAt(screen, "[L] close  [ / ] metric  [, / .] window", x+8, y+6+(rows+3)*lineHeight)
+}
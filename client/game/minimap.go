@@ -0,0 +1,216 @@
+package game
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/z46-dev/game-dev-project/shared/definitions"
+	"github.com/z46-dev/game-dev-project/shared/protocol"
+	"github.com/z46-dev/game-dev-project/util"
+	"golang.org/x/image/colornames"
+)
+
+const (
+	MINIMAP_SIZE   float32 = 200
+	MINIMAP_MARGIN float32 = 12
+)
+
+var (
+	mapBackgroundColor color.RGBA = color.RGBA{R: 8, G: 16, B: 32, A: 200}
+	mapIslandColor     color.RGBA = color.RGBA{R: 85, G: 107, B: 47, A: 255}
+	mapNeutralColor    color.RGBA = color.RGBA{R: 160, G: 160, B: 160, A: 255}
+)
+
+func (g *Game) ParseMapUpdate(reader *protocol.Reader) {
+	var update protocol.MapUpdate
+	if update.Read(reader) != nil {
+		return
+	}
+
+	var state *MapState = &MapState{
+		ServerTime: int(update.Time),
+		Extent:     float64(update.Extent),
+		Factions:   make(map[uint32]*MapFaction),
+	}
+
+	var toWorld = func(point protocol.MapPoint) *util.Vector2D {
+		return util.Vector(protocol.DequantizeCoordinate(point.X, state.Extent), protocol.DequantizeCoordinate(point.Y, state.Extent))
+	}
+
+	if update.HasStatic == 1 {
+		state.Islands = make([][]*util.Vector2D, len(update.Islands))
+		for i, island := range update.Islands {
+			state.Islands[i] = make([]*util.Vector2D, len(island.Points))
+			for j, point := range island.Points {
+				state.Islands[i][j] = toWorld(point)
+			}
+		}
+	} else {
+		// Keep the static obstacles from the first update
+		g.MapMu.RLock()
+		if g.Map != nil {
+			state.Islands = g.Map.Islands
+		}
+		g.MapMu.RUnlock()
+	}
+
+	for _, f := range update.Factions {
+		state.Factions[f.ID] = &MapFaction{
+			ID:    f.ID,
+			Color: color.RGBA{R: f.R, G: f.G, B: f.B, A: 255},
+			Name:  f.Name,
+		}
+	}
+
+	state.Objectives = make([]*MapObjective, len(update.Objectives))
+	for i, o := range update.Objectives {
+		state.Objectives[i] = &MapObjective{
+			Position: toWorld(o.Position),
+			Radius:   float64(o.Radius),
+			Owner:    o.Owner,
+			Capturer: o.Capturer,
+			Progress: float64(o.Progress) / 255,
+			Name:     o.Name,
+		}
+	}
+
+	state.Ships = make([]*MapShip, len(update.Ships))
+	for i, s := range update.Ships {
+		state.Ships[i] = &MapShip{
+			Position:       toWorld(s.Position),
+			Rotation:       protocol.DequantizeAngle8(s.Rotation),
+			Faction:        s.Faction,
+			Classification: definitions.ShipClassification(s.Classification),
+			Flags:          s.Flags,
+		}
+	}
+
+	g.MapMu.Lock()
+	g.Map = state
+	g.MapMu.Unlock()
+}
+
+func (state *MapState) factionColor(id uint32) color.Color {
+	if faction, ok := state.Factions[id]; ok {
+		return faction.Color
+	}
+
+	return mapNeutralColor
+}
+
+// Draws the map into the square at (x, y) with the given side length
+func (state *MapState) draw(g *Game, screen *ebiten.Image, x, y, size float32, labels bool) {
+	var toMap = func(position *util.Vector2D) (float32, float32) {
+		return x + float32((position.X+state.Extent)/(state.Extent*2))*size, y + float32((position.Y+state.Extent)/(state.Extent*2))*size
+	}
+
+	var scale float32 = size / float32(state.Extent*2)
+
+	vector.FillRect(screen, x, y, size, size, mapBackgroundColor, false)
+	vector.StrokeRect(screen, x, y, size, size, 1, colornames.Lightsteelblue, false)
+
+	for _, island := range state.Islands {
+		var path *vector.Path = &vector.Path{}
+		for i, point := range island {
+			var px, py float32 = toMap(point)
+			if i == 0 {
+				path.MoveTo(px, py)
+			} else {
+				path.LineTo(px, py)
+			}
+		}
+
+		path.Close()
+
+		var opts *vector.DrawPathOptions = &vector.DrawPathOptions{AntiAlias: true}
+		opts.ColorScale.ScaleWithColor(mapIslandColor)
+		vector.FillPath(screen, path, &vector.FillOptions{}, opts)
+	}
+
+	for _, o := range state.Objectives {
+		var ox, oy float32 = toMap(o.Position)
+		var radius float32 = max(3, float32(o.Radius)*scale)
+		vector.StrokeCircle(screen, ox, oy, radius, 1.5, state.factionColor(o.Owner), true)
+
+		if o.Capturer != 0 && o.Progress > 0 {
+			var arc *vector.Path = &vector.Path{}
+			arc.Arc(ox, oy, radius+2, -math.Pi/2, -math.Pi/2+float32(o.Progress*math.Pi*2), vector.Clockwise)
+
+			var opts *vector.DrawPathOptions = &vector.DrawPathOptions{AntiAlias: true}
+			opts.ColorScale.ScaleWithColor(state.factionColor(o.Capturer))
+			vector.StrokePath(screen, arc, &vector.StrokeOptions{Width: 2}, opts)
+		}
+
+		if labels {
+			ebitenutil.DebugPrintAt(screen, o.Name, int(ox)-len(o.Name)*3, int(oy)-8)
+		}
+	}
+
+	for _, ship := range state.Ships {
+		var sx, sy float32 = toMap(ship.Position)
+		var radius float32 = 2.5
+		var col color.Color = state.factionColor(ship.Faction)
+		if ship.Flags&protocol.BITFLAG_MAP_SHIP_SELF != 0 {
+			radius = 4
+			col = colornames.White
+		}
+
+		var heading float32 = radius * 2.5
+		vector.StrokeLine(screen, sx, sy, sx+float32(math.Cos(ship.Rotation))*heading, sy+float32(math.Sin(ship.Rotation))*heading, 1, col, true)
+		vector.FillCircle(screen, sx, sy, radius, col, true)
+
+		if ship.Flags&protocol.BITFLAG_MAP_SHIP_ALLIED == 0 {
+			vector.StrokeCircle(screen, sx, sy, radius+1.5, 1, colornames.Red, true)
+		}
+	}
+
+	// Current view
+	var viewWidth, viewHeight float32 = float32(g.Camera.Width/g.Camera.Zoom) * scale, float32(g.Camera.Height/g.Camera.Zoom) * scale
+	var cx, cy float32 = toMap(g.Camera.Position)
+	vector.StrokeRect(screen, cx-viewWidth/2, cy-viewHeight/2, viewWidth, viewHeight, 1, colornames.White, false)
+}
+
+func (g *Game) drawMap(screen *ebiten.Image) {
+	g.MapMu.RLock()
+	var state *MapState = g.Map
+	g.MapMu.RUnlock()
+
+	if state == nil {
+		return
+	}
+
+	var bounds = screen.Bounds()
+	var width, height float32 = float32(bounds.Dx()), float32(bounds.Dy())
+
+	if g.ShowTacticalMap {
+		var size float32 = min(width, height) * 0.9
+		vector.FillRect(screen, 0, 0, width, height, color.RGBA{A: 160}, false)
+		state.draw(g, screen, (width-size)/2, (height-size)/2, size, true)
+		return
+	}
+
+	state.draw(g, screen, width-MINIMAP_SIZE-MINIMAP_MARGIN, height-MINIMAP_SIZE-MINIMAP_MARGIN, MINIMAP_SIZE, false)
+}
+
+// Objectives are only known through the map stream, so their zones are drawn from it
+func (g *Game) drawObjectiveZones(screen *ebiten.Image) {
+	g.MapMu.RLock()
+	var state *MapState = g.Map
+	g.MapMu.RUnlock()
+
+	if state == nil {
+		return
+	}
+
+	for _, o := range state.Objectives {
+		if !g.Camera.IsInView(o.Position, o.Radius) {
+			continue
+		}
+
+		var x, y float32 = float32((o.Position.X-g.Camera.Position.X)*g.Camera.Zoom + g.Camera.Width/2), float32((o.Position.Y-g.Camera.Position.Y)*g.Camera.Zoom + g.Camera.Height/2)
+		vector.StrokeCircle(screen, x, y, float32(o.Radius*g.Camera.Zoom), 3, state.factionColor(o.Owner), true)
+	}
+}
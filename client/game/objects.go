@@ -82,10 +82,18 @@ func turretTextureIndex(shipID uint64, turretIndex int, count int) int {
 }
 
 func (s *ClientShip) Draw(game *Game, screen *ebiten.Image) {
-	s.Position.X = util.Lerp(s.Position.X, s.RealPosition.X, .3)
-	s.Position.Y = util.Lerp(s.Position.Y, s.RealPosition.Y, .3)
-	s.Size = util.Lerp(s.Size, s.RealSize, .3)
-	s.Rotation = util.LerpAngle(s.Rotation, s.RealRotation, .3)
+	if snapshot, ok := s.Snapshots.Sample(game.renderTick); ok {
+		s.Position.X, s.Position.Y = snapshot.X, snapshot.Y
+		s.Size, s.Rotation = snapshot.Size, snapshot.Rotation
+	} else {
+		s.Position.X, s.Position.Y = s.RealPosition.X, s.RealPosition.Y
+		s.Size, s.Rotation = s.RealSize, s.RealRotation
+	}
+
+	if game.ownSnapshot != nil && s.ID == game.PlayerID {
+		s.Position.X, s.Position.Y = game.ownSnapshot.X, game.ownSnapshot.Y
+		s.Rotation = game.ownSnapshot.Rotation
+	}
 
 	var bounds image.Rectangle = s.asset.Bounds()
 	var dx, dy float64 = float64(bounds.Dx()), float64(bounds.Dy())
@@ -124,4 +132,29 @@ func (s *ClientShip) drawHealthBar(game *Game, screen *ebiten.Image) {
 
 	vector.FillRect(screen, x-1, y-1, barWidth+2, barHeight+2, colornames.Black, true)
 	vector.FillRect(screen, x, y, barWidth*float32(s.HealthRatio), barHeight, colornames.Limegreen, true)
-}
\ No newline at end of file
+}
+
+func (i *ClientIsland) Draw(game *Game, screen *ebiten.Image) {
+	if !game.Camera.IsInView(i.Position, i.Size/2) {
+		return
+	}
+
+	var bounds image.Rectangle = i.asset.Bounds()
+	var dx, dy float64 = float64(bounds.Dx()), float64(bounds.Dy())
+
+	var options *ebiten.DrawImageOptions = &ebiten.DrawImageOptions{}
+
+	// Object transformations
+	options.GeoM.Translate(-dx/2, -dy/2)
+	options.GeoM.Scale(i.Size/dx, i.Size/dy)
+	options.GeoM.Rotate(i.Rotation)
+	options.GeoM.Translate(i.Position.X, i.Position.Y)
+
+	// Camera transformations
+	options.GeoM.Scale(game.Camera.Zoom, game.Camera.Zoom)
+	options.GeoM.Translate(game.Camera.Width/2, game.Camera.Height/2)
+	options.GeoM.Translate(-game.Camera.Position.X*game.Camera.Zoom, -game.Camera.Position.Y*game.Camera.Zoom)
+
+	options.Filter = ebiten.FilterLinear
+	screen.DrawImage(i.asset, options)
+}
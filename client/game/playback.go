@@ -0,0 +1,192 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/z46-dev/game-dev-project/client/demo"
+	"github.com/z46-dev/game-dev-project/util"
+)
+
+const (
+	PLAYBACK_SEEK_STEP      time.Duration = 5 * time.Second
+	PLAYBACK_SEEK_LONG_STEP time.Duration = 30 * time.Second // With Shift held
+	PLAYBACK_MIN_SPEED      float64       = 0.125
+	PLAYBACK_MAX_SPEED      float64       = 8
+	FREE_CAMERA_SPEED       float64       = 12 // Screen pixels per frame
+)
+
+func NewPlayback(d *demo.Demo) *Playback {
+	return &Playback{
+		Demo:   d,
+		Speed:  1,
+		Camera: util.Vector(0, 0),
+		Zoom:   1,
+	}
+}
+
+// The demo's clock while playing back, the wall clock otherwise. Interpolation runs on it, so pausing and
+// changing speed move everything on screen together.
+func (g *Game) now() time.Time {
+	if g.Playback != nil {
+		return g.Playback.Demo.Header.Start.Add(g.Playback.Position)
+	}
+
+	return time.Now()
+}
+
+// Handles every record up to target in order, each at the time it originally arrived
+func (p *Playback) feed(g *Game, target time.Duration) {
+	for ; p.next < len(p.Demo.Records) && p.Demo.Records[p.next].At <= target; p.next++ {
+		var record demo.Record = p.Demo.Records[p.next]
+		p.Position = record.At
+
+		messageType, err := g.HandleMessage(record.Message)
+		if p.OnMessage != nil {
+			p.OnMessage(messageType, err)
+		}
+	}
+
+	p.Position = target
+}
+
+// Jumps to target. View updates only carry changes, so going back replays from the start.
+func (p *Playback) Seek(g *Game, target time.Duration) {
+	target = max(0, min(target, p.Demo.Duration()))
+	if target < p.Position {
+		g.resetWorld()
+		p.next, p.Position = 0, 0
+	}
+
+	p.feed(g, target)
+}
+
+// Forgets everything the server said, as if the connection had just opened
+func (g *Game) resetWorld() {
+	g.ShipsMu.Lock()
+	g.Ships = make(map[uint64]*ClientShip)
+	g.ShipsMu.Unlock()
+
+	g.IslandsMu.Lock()
+	g.Islands = make(map[uint64]*ClientIsland)
+	g.IslandsMu.Unlock()
+
+	g.HUDMu.Lock()
+	g.HUD = nil
+	g.HUDMu.Unlock()
+
+	g.MapMu.Lock()
+	g.Map = nil
+	g.MapMu.Unlock()
+
+	g.ChatMu.Lock()
+	g.Chat.Messages = nil
+	g.ChatMu.Unlock()
+
+	g.Camera.Snapshots = NewSnapshotBuffer()
+	g.Clock = NewServerClock()
+	g.Prediction = NewPrediction()
+	g.Session, g.Spectator = nil, nil
+	g.ServerTime, g.PlayerID, g.DisconnectReason = 0, 0, ""
+}
+
+// Space pauses, Left/Right seek (further with Shift), Up/Down change speed, Home restarts, F frees the
+// camera, which then pans with WASD and zooms with the wheel
+func (g *Game) updatePlayback() {
+	var (
+		p       *Playback = g.Playback
+		now     time.Time = time.Now()
+		elapsed time.Duration
+		step    time.Duration = PLAYBACK_SEEK_STEP
+	)
+
+	if !p.lastFrame.IsZero() {
+		elapsed = now.Sub(p.lastFrame)
+	}
+
+	p.lastFrame = now
+
+	if ebiten.IsKeyPressed(ebiten.KeyShift) {
+		step = PLAYBACK_SEEK_LONG_STEP
+	}
+
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeySpace):
+		p.Paused = !p.Paused
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowRight):
+		p.Seek(g, p.Position+step)
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft):
+		p.Seek(g, p.Position-step)
+	case inpututil.IsKeyJustPressed(ebiten.KeyHome):
+		p.Seek(g, 0)
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowUp):
+		p.Speed = min(p.Speed*2, PLAYBACK_MAX_SPEED)
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowDown):
+		p.Speed = max(p.Speed/2, PLAYBACK_MIN_SPEED)
+	case inpututil.IsKeyJustPressed(ebiten.KeyF):
+		if p.FreeCam = !p.FreeCam; p.FreeCam {
+			p.Camera = g.Camera.Position.Copy()
+			p.Zoom = g.Camera.RealZoom
+		}
+	}
+
+	if !p.Paused {
+		p.feed(g, min(p.Position+time.Duration(float64(elapsed)*p.Speed), p.Demo.Duration()))
+	}
+
+	if !p.FreeCam {
+		return
+	}
+
+	var speed float64 = FREE_CAMERA_SPEED / g.Camera.Zoom
+	if ebiten.IsKeyPressed(ebiten.KeyA) {
+		p.Camera.X -= speed
+	}
+
+	if ebiten.IsKeyPressed(ebiten.KeyD) {
+		p.Camera.X += speed
+	}
+
+	if ebiten.IsKeyPressed(ebiten.KeyW) {
+		p.Camera.Y -= speed
+	}
+
+	if ebiten.IsKeyPressed(ebiten.KeyS) {
+		p.Camera.Y += speed
+	}
+
+	if _, wheel := ebiten.Wheel(); wheel != 0 {
+		p.Zoom = math.Max(math.Min(p.Zoom*math.Pow(1.1, wheel), 5), .05)
+	}
+
+	// View updates keep setting the recorded zoom, this wins since it runs after them
+	g.Camera.RealZoom = p.Zoom
+}
+
+func (g *Game) drawPlayback(screen *ebiten.Image) {
+	if g.Playback == nil {
+		return
+	}
+
+	var (
+		p      *Playback = g.Playback
+		width  int       = screen.Bounds().Dx()
+		status string    = fmt.Sprintf("REPLAY %s / %s  x%g", formatClock(int(p.Position.Seconds())), formatClock(int(p.Demo.Duration().Seconds())), p.Speed)
+		help   string    = "[Space] pause  [Left/Right] seek  [Up/Down] speed  [Home] restart  [F] free camera"
+	)
+
+	if p.Paused {
+		status += "  PAUSED"
+	}
+
+	if p.FreeCam {
+		status += "  FREE CAMERA"
+	}
+
+	ebitenutil.DebugPrintAt(screen, status, width/2-len(status)*3, 28)
+	ebitenutil.DebugPrintAt(screen, help, width/2-len(help)*3, 44)
+}
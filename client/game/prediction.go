@@ -0,0 +1,209 @@
+package game
+
+import (
+	"time"
+
+	"github.com/z46-dev/game-dev-project/shared/definitions"
+	"github.com/z46-dev/game-dev-project/shared/movement"
+	"github.com/z46-dev/game-dev-project/shared/protocol"
+	"github.com/z46-dev/game-dev-project/util"
+)
+
+const (
+	MAX_PENDING_INPUTS       int     = 128  // Four seconds at 30 TPS, anything older is never getting acknowledged
+	MAX_CATCH_UP_STEPS       int     = 5    // Steps taken in one frame after a stall before the schedule restarts
+	PREDICTION_ERROR_DECAY   float64 = 0.85 // Fraction of a correction still drawn after each frame
+	PREDICTION_SNAP_DISTANCE float64 = 200  // Corrections further than this (respawns, hard collisions) are not smoothed
+)
+
+func NewPrediction() *Prediction {
+	return &Prediction{Error: util.Vector(0, 0)}
+}
+
+func bodyFromHeader(header *protocol.ViewHeader) *movement.Body {
+	return &movement.Body{
+		Position: util.Vector(float64(header.X), float64(header.Y)),
+		Velocity: util.Vector(float64(header.VelocityX), float64(header.VelocityY)),
+		Rotation: float64(header.Rotation),
+	}
+}
+
+// Starts over from the server's state after the acknowledged input and replays every input it has not
+// applied yet. The jump this causes is moved into Error so it is smoothed out rather than drawn.
+func (p *Prediction) Reconcile(header *protocol.ViewHeader) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var server *movement.Body = bodyFromHeader(header)
+	if header.EntityID != p.EntityID || p.Body == nil {
+		p.EntityID = header.EntityID
+		p.Body, p.Previous = server, server.Copy()
+		p.Pending = p.Pending[:0]
+		p.Error = util.Vector(0, 0)
+		return
+	}
+
+	var acknowledged int
+	for acknowledged < len(p.Pending) && p.Pending[acknowledged].Sequence <= header.InputAck {
+		acknowledged++
+	}
+
+	p.Pending = append(p.Pending[:0], p.Pending[acknowledged:]...)
+
+	for _, input := range p.Pending {
+		server.Step(input.Goal, input.Handling)
+	}
+
+	var dx, dy, dr float64 = server.Position.X - p.Body.Position.X, server.Position.Y - p.Body.Position.Y, server.Rotation - p.Body.Rotation
+	p.Body = server
+	p.Previous.Position.X += dx
+	p.Previous.Position.Y += dy
+	p.Previous.Rotation += dr
+
+	p.Error.X -= dx
+	p.Error.Y -= dy
+	if p.Error.Magnitude() > PREDICTION_SNAP_DISTANCE {
+		p.Error = util.Vector(0, 0)
+	}
+}
+
+// Stops predicting, the player no longer has a body
+func (p *Prediction) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.EntityID = 0
+	p.Body, p.Previous = nil, nil
+	p.Pending = p.Pending[:0]
+}
+
+// Applies one input locally and remembers it until the server acknowledges it
+func (p *Prediction) step(input *protocol.Input, h movement.Handling) {
+	var goal *util.Vector2D = movement.Goal(input.Flags)
+	p.Previous = p.Body.Copy()
+	p.Body.Step(goal, h)
+
+	if len(p.Pending) == MAX_PENDING_INPUTS {
+		p.Pending = append(p.Pending[:0], p.Pending[1:]...)
+	}
+
+	p.Pending = append(p.Pending, PendingInput{Sequence: input.Sequence, Goal: goal, Handling: h})
+}
+
+// Where to draw the body this frame, between the last two steps plus whatever correction is left
+func (p *Prediction) Sample(now time.Time, interval time.Duration) (s Snapshot, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Body == nil {
+		return
+	}
+
+	var t float64 = min(1, max(0, 1-float64(p.nextStep.Sub(now))/float64(interval)))
+	s.X = util.Lerp(p.Previous.Position.X, p.Body.Position.X, t) + p.Error.X
+	s.Y = util.Lerp(p.Previous.Position.Y, p.Body.Position.Y, t) + p.Error.Y
+	s.Rotation = util.LerpAngle(p.Previous.Rotation, p.Body.Rotation, t)
+	return s, true
+}
+
+// How the own ship handles right now, engine boosts included. False until its definition is known.
+func (g *Game) ownHandling() (h movement.Handling, ok bool) {
+	g.ShipsMu.RLock()
+	var ship *ClientShip = g.Ships[g.PlayerID]
+	g.ShipsMu.RUnlock()
+
+	if ship == nil || ship.Definition == nil {
+		return
+	}
+
+	var multiplier float64 = 1
+	g.HUDMu.RLock()
+	if g.HUD != nil {
+		for _, c := range g.HUD.Consumables {
+			if c.Active > 0 && c.Definition != nil && c.Definition.ID == definitions.CONSUMABLE_ENGINE_BOOST {
+				multiplier *= c.Definition.Strength
+			}
+		}
+	}
+	g.HUDMu.RUnlock()
+
+	h = movement.Handling{
+		Acceleration: movement.Acceleration(ship.Definition.Speed, multiplier),
+		TurnSpeed:    ship.Definition.TurnSpeed,
+		Friction:     movement.DEFAULT_FRICTION,
+	}
+
+	return h, true
+}
+
+func (g *Game) tickInterval() time.Duration {
+	return time.Second / time.Duration(max(1, g.Session.TickRate))
+}
+
+// Whether the own ship is predicted, which needs a v4 connection and a body. Demos hold no inputs to
+// predict from, so there the own ship is interpolated like every other.
+func (g *Game) predicting() bool {
+	return g.Protocol >= protocol.PROTOCOL_PREDICTION_VERSION && g.Session != nil && g.Spectator == nil && g.Playback == nil
+}
+
+// Numbers an input sent without predicting, from v4 every input has to count up even while spectating
+func (g *Game) nextSequence() (sequence uint32) {
+	if g.Protocol < protocol.PROTOCOL_PREDICTION_VERSION {
+		return
+	}
+
+	g.Prediction.mu.Lock()
+	g.Prediction.Sequence++
+	sequence = g.Prediction.Sequence
+	g.Prediction.mu.Unlock()
+	return
+}
+
+// Sends one sequenced input per server tick and steps the prediction with each. Keys are sampled every
+// frame, only frames a tick falls on send anything. Returns whether an input went out.
+func (g *Game) updatePrediction(flags uint8) (sent bool) {
+	var (
+		p        *Prediction   = g.Prediction
+		now      time.Time     = time.Now()
+		interval time.Duration = g.tickInterval()
+	)
+
+	h, known := g.ownHandling()
+
+	p.mu.Lock()
+	p.Error.Scale(PREDICTION_ERROR_DECAY)
+
+	if p.nextStep.IsZero() || now.Sub(p.nextStep) > interval*time.Duration(MAX_CATCH_UP_STEPS) {
+		p.nextStep = now
+	}
+
+	var inputs []*protocol.Input
+	for !now.Before(p.nextStep) {
+		p.nextStep = p.nextStep.Add(interval)
+		p.Sequence++
+
+		var input *protocol.Input = &protocol.Input{
+			Flags:    flags,
+			MouseX:   float32(g.MousePosition.X),
+			MouseY:   float32(g.MousePosition.Y),
+			Sequence: p.Sequence,
+			ViewTick: g.viewTick(),
+		}
+
+		if p.Body != nil && known {
+			p.step(input, h)
+		}
+
+		inputs = append(inputs, input)
+	}
+	p.mu.Unlock()
+
+	for _, input := range inputs {
+		var w *protocol.Writer = g.NewWriter()
+		w.SetU8(protocol.PACKET_SERVERBOUND_INPUT)
+		input.Write(w)
+		g.Socket.Write(w.GetBytes())
+	}
+
+	return len(inputs) > 0
+}
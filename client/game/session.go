@@ -0,0 +1,120 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/z46-dev/game-dev-project/shared/definitions"
+	"github.com/z46-dev/game-dev-project/shared/protocol"
+	"github.com/z46-dev/game-dev-project/util"
+)
+
+func (g *Game) NewWriter() *protocol.Writer {
+	return protocol.NewWriter(g.Protocol)
+}
+
+// First packet on every connection, the server ignores everything else until it accepts. The version
+// prefix tells the server which wire format the rest of the connection uses. An empty token joins as a guest,
+// an empty loadout takes the ship's default squadrons.
+func (g *Game) SendJoin(name string, shipID uint8, room, token string, loadout []uint8) {
+	var w *protocol.Writer = g.NewWriter()
+	w.SetU8(protocol.PACKET_SERVERBOUND_JOIN)
+	w.SetU16(g.Protocol)
+	(&protocol.Join{
+		Name:    name,
+		ShipID:  shipID,
+		Room:    room,
+		Token:   token,
+		Loadout: loadout,
+	}).Write(w)
+	g.Socket.Write(w.GetBytes())
+}
+
+// Routes one message from the server, live or from a demo, to its parser. Join accepts that do not
+// match the local definitions, unknown packets and malformed ones come back as errors.
+func (g *Game) HandleMessage(message []byte) (messageType uint8, err error) {
+	var reader *protocol.Reader = protocol.NewReader(message)
+	messageType = reader.GetU8()
+	reader.Version = g.Protocol
+
+	switch messageType {
+	case protocol.PACKET_CLIENTBOUND_JOIN_ACCEPT:
+		err = g.ParseJoinAccept(reader)
+	case protocol.PACKET_CLIENTBOUND_JOIN_REJECT, protocol.PACKET_CLIENTBOUND_KICK:
+		g.ParseDisconnect(reader)
+	case protocol.PACKET_CLIENTBOUND_VIEW_UPDATE:
+		g.ParseViewUpdate(reader)
+	case protocol.PACKET_CLIENTBOUND_MAP_UPDATE:
+		g.ParseMapUpdate(reader)
+	case protocol.PACKET_CLIENTBOUND_GUI_UPDATE:
+		g.ParseGUIUpdate(reader)
+	case protocol.PACKET_CLIENTBOUND_CHAT:
+		g.ParseChat(reader)
+	default:
+		return messageType, fmt.Errorf("unknown message type %d", messageType)
+	}
+
+	if reader.Err() != nil {
+		err = fmt.Errorf("malformed packet %d: %w", messageType, reader.Err())
+	}
+
+	return
+}
+
+func (g *Game) ParseJoinAccept(reader *protocol.Reader) (err error) {
+	var accept protocol.JoinAccept
+	if err = accept.Read(reader); err != nil {
+		return
+	}
+
+	g.Session = &Session{
+		PlayerID:          accept.PlayerID,
+		EntityID:          accept.EntityID,
+		TickRate:          int(accept.TickRate),
+		DefinitionsHash:   accept.DefinitionsHash,
+		PositionPrecision: float64(accept.PositionPrecision),
+	}
+
+	g.Clock.SetTickRate(float64(g.Session.TickRate))
+
+	if g.Session.DefinitionsHash != definitions.Hash() {
+		err = fmt.Errorf("definitions hash mismatch (server %08x, client %08x), ships may not match the server", g.Session.DefinitionsHash, definitions.Hash())
+	}
+
+	return
+}
+
+// Quantized offsets are relative to the camera center from the current view header
+func (g *Game) dequantizePosition(offsetX, offsetY uint16) *util.Vector2D {
+	var precision float64 = 1
+	if g.Session != nil {
+		precision = g.Session.PositionPrecision
+	}
+
+	return util.Vector(
+		protocol.DequantizeOffset(offsetX, g.Camera.RealPosition.X, precision),
+		protocol.DequantizeOffset(offsetY, g.Camera.RealPosition.Y, precision),
+	)
+}
+
+// Join rejections and kicks both carry a human readable reason
+func (g *Game) ParseDisconnect(reader *protocol.Reader) {
+	var disconnect protocol.Disconnect
+	if disconnect.Read(reader) != nil {
+		g.DisconnectReason = "Connection closed"
+		return
+	}
+
+	g.DisconnectReason = disconnect.Reason
+}
+
+func (g *Game) drawDisconnect(screen *ebiten.Image) {
+	if g.DisconnectReason == "" {
+		return
+	}
+
+	var bounds = screen.Bounds()
+	var text string = "Disconnected: " + g.DisconnectReason
+	ebitenutil.DebugPrintAt(screen, text, bounds.Dx()/2-len(text)*3, bounds.Dy()/2)
+}
@@ -3,10 +3,13 @@ package game
 import (
 	"image/color"
 	"sync"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/z46-dev/game-dev-project/client/demo"
 	"github.com/z46-dev/game-dev-project/client/web"
 	"github.com/z46-dev/game-dev-project/shared/definitions"
+	"github.com/z46-dev/game-dev-project/shared/movement"
 	"github.com/z46-dev/game-dev-project/util"
 )
 
@@ -21,6 +24,60 @@ type (
 
 		RealPosition *util.Vector2D
 		RealZoom     float64
+		Snapshots    *SnapshotBuffer // Header positions, so the camera renders at the same delay as ships
+	}
+
+	// Entity state as of a server tick
+	Snapshot struct {
+		Tick                 int
+		X, Y, Size, Rotation float64
+	}
+
+	// Recent snapshots of one entity, oldest first. Written by the socket, sampled while drawing.
+	SnapshotBuffer struct {
+		mu        sync.Mutex
+		snapshots []Snapshot
+	}
+
+	// Estimates the server tick right now from when view updates arrive
+	ServerClock struct {
+		mu       sync.Mutex
+		offset   float64 // Server ticks minus local ticks
+		synced   bool
+		TickRate float64
+	}
+
+	// An input sent but not yet acknowledged, replayed on top of every correction until it is
+	PendingInput struct {
+		Sequence uint32
+		Goal     *util.Vector2D
+		Handling movement.Handling
+	}
+
+	// The player's own ship simulated locally, one step per input, so it responds before the server does
+	Prediction struct {
+		mu             sync.Mutex
+		EntityID       uint64         // Body being predicted, 0 until the first view header with one
+		Body, Previous *movement.Body // After the newest input, and the one before it for smoothing between ticks
+		Pending        []PendingInput
+		Sequence       uint32         // Last input sent
+		Error          *util.Vector2D // Left over from the last correction, drawn on top and decayed away
+		nextStep       time.Time
+	}
+
+	// Feeds a recorded demo through the same parsers as a live connection, on a clock that can be paused,
+	// sped up and moved
+	Playback struct {
+		Demo      *demo.Demo
+		Position  time.Duration // Demo time on screen
+		Speed     float64
+		Paused    bool
+		FreeCam   bool
+		Camera    *util.Vector2D                     // Free camera center
+		Zoom      float64                            // Free camera zoom
+		OnMessage func(messageType uint8, err error) // Called after each record is handled, for logging
+		next      int                                // First record not handled yet
+		lastFrame time.Time
 	}
 
 	ClientShip struct {
@@ -33,17 +90,142 @@ type (
 		Outline                                color.Color
 		Definition                             *definitions.Ship
 		HealthRatio                            float64
+		Snapshots                              *SnapshotBuffer
+	}
+
+	ClientIsland struct {
+		ID             uint64
+		Position       *util.Vector2D
+		Size, Rotation float64
+		asset          *ebiten.Image
+	}
+
+	SpectatorState struct {
+		TargetID             uint64
+		FreeCam, AllFactions bool
+		FOV                  float64
+	}
+
+	MapFaction struct {
+		ID    uint32
+		Color color.RGBA
+		Name  string
+	}
+
+	MapObjective struct {
+		Position        *util.Vector2D
+		Radius          float64
+		Owner, Capturer uint32
+		Progress        float64
+		Name            string
+	}
+
+	MapShip struct {
+		Position       *util.Vector2D
+		Rotation       float64
+		Faction        uint32
+		Classification definitions.ShipClassification
+		Flags          uint8
+	}
+
+	// Latest coarse overview of the world, refreshed by PACKET_CLIENTBOUND_MAP_UPDATE
+	MapState struct {
+		ServerTime int
+		Extent     float64
+		Islands    [][]*util.Vector2D
+		Factions   map[uint32]*MapFaction
+		Objectives []*MapObjective
+		Ships      []*MapShip
+	}
+
+	HUDSquadron struct {
+		Plane                   *definitions.Plane
+		Planes, HangarSize      int
+		LaunchTimer, RegenTimer float64 // Seconds
+	}
+
+	HUDConsumable struct {
+		Definition       *definitions.Consumable
+		Charges          int // -1 for unlimited
+		Cooldown, Active float64
+	}
+
+	// Latest GUI state, refreshed by PACKET_CLIENTBOUND_GUI_UPDATE
+	HUDState struct {
+		HasBody                bool
+		Ship                   *definitions.Ship
+		Health, MaxHealth      float64
+		Speed, MaxSpeed        float64
+		Squadrons              []*HUDSquadron
+		Consumables            []*HUDConsumable
+		Score                  int
+		MatchTime, MatchLength int // Seconds
+	}
+
+	ChatMessage struct {
+		Channel         uint8
+		Sender, Message string
+		ReceivedAt      int // LocalTime when it arrived
+	}
+
+	ChatState struct {
+		Messages []*ChatMessage
+		Typing   bool
+		Input    []rune
+	}
+
+	// The leaderboard screen, fetched from the server's HTTP side while it is open
+	LeaderboardState struct {
+		Open           bool
+		Metric, Window int              // Indices into leaderboardMetrics and leaderboardWindows
+		Board          *web.Leaderboard // For the current metric and window, nil until it arrives
+		Error          string
+		fetchedAt      time.Time
+		loading        bool
+	}
+
+	// Handshake result from PACKET_CLIENTBOUND_JOIN_ACCEPT
+	Session struct {
+		PlayerID          uint32
+		EntityID          uint64
+		TickRate          int
+		DefinitionsHash   uint32
+		PositionPrecision float64 // World units per step of a quantized position offset
 	}
 
 	Game struct {
 		ServerTime, LocalTime int
 		Camera                *PlayerCamera
 		PlayerID              uint64
+		Spectator             *SpectatorState // nil while the player has a body
 		Socket                *web.Socket
+		Session               *Session
+		Clock                 *ServerClock
+		Prediction            *Prediction
+		InterpolationDelay    time.Duration // How far in the past entities are rendered
+		renderTick            float64       // Server tick being drawn this frame
+		Protocol              uint16        // Wire format used on the connection, chosen when joining
+		DisconnectReason      string
 		lastInputFlags        uint8
+		mouseMoved            bool      // The cursor moved since the last input went out
+		ownSnapshot           *Snapshot // Predicted own ship for this frame, nil when not predicting
+		Playback              *Playback // nil on a live connection
+		ServerAddress         string    // WebSocket address the HTTP endpoints share, empty during playback
+
+		Ships     map[uint64]*ClientShip
+		ShipsMu   sync.RWMutex
+		Islands   map[uint64]*ClientIsland
+		IslandsMu sync.RWMutex
 
-		Ships         map[uint64]*ClientShip
-		ShipsMu       sync.RWMutex
+		HUD             *HUDState
+		HUDMu           sync.RWMutex
+		Map             *MapState
+		Chat            *ChatState
+		ChatMu          sync.Mutex
+		MapMu           sync.RWMutex
+		ShowTacticalMap bool
+		Leaderboard     *LeaderboardState
+		LeaderboardMu   sync.Mutex
 
 		MousePosition *util.Vector2D
 	}
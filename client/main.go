@@ -1,11 +1,17 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/z46-dev/game-dev-project/client/demo"
 	"github.com/z46-dev/game-dev-project/client/game"
 	"github.com/z46-dev/game-dev-project/client/web"
+	"github.com/z46-dev/game-dev-project/shared/definitions"
 	"github.com/z46-dev/game-dev-project/shared/protocol"
 	"github.com/z46-dev/golog"
 )
@@ -15,6 +21,68 @@ var (
 	err error
 )
 
+// Logs what a handled message means for the player, live or played back
+func report(g *game.Game, messageType uint8, err error) {
+	switch messageType {
+	case protocol.PACKET_CLIENTBOUND_JOIN_ACCEPT:
+		if g.Session != nil {
+			log.Infof("Joined as player #%d at %d TPS", g.Session.PlayerID, g.Session.TickRate)
+		}
+	case protocol.PACKET_CLIENTBOUND_JOIN_REJECT, protocol.PACKET_CLIENTBOUND_KICK:
+		log.Errorf("Disconnected by server: %s", g.DisconnectReason)
+	}
+
+	if err != nil {
+		log.Warning(err.Error())
+	}
+}
+
+// Turns "0,0,2" into variant indices and checks them against the ship, listing what each slot offers if they
+// do not fit
+func parseLoadout(shipID definitions.ShipID, text string) (loadout []uint8, err error) {
+	def, found := definitions.GetByKey(definitions.ShipConfigs, shipID)
+	if !found || text == "" {
+		return
+	}
+
+	for _, part := range strings.Split(text, ",") {
+		var index uint64
+		if index, err = strconv.ParseUint(strings.TrimSpace(part), 10, 8); err != nil {
+			return nil, err
+		}
+
+		loadout = append(loadout, uint8(index))
+	}
+
+	if _, err = def.Loadout(loadout); err != nil {
+		for slot, variants := range def.Variants {
+			var labels []string
+			for i, squadron := range variants {
+				labels = append(labels, fmt.Sprintf("%d = %s", i, squadron.Label()))
+			}
+
+			log.Infof("Slot %d: %s", slot+1, strings.Join(labels, ", "))
+		}
+	}
+
+	return
+}
+
+// Prints the wallet and which ships the account can spawn as or unlock next
+func logProgress(progress web.Progress) {
+	log.Infof("%d XP, %d credits", progress.XP, progress.Credits)
+	for _, def := range definitions.SortedShips() {
+		switch {
+		case definitions.CanSelect(def, progress.Ships):
+			log.Infof("  [%d] %s: unlocked", def.ID, def.Name)
+		case definitions.CanResearch(def, progress.Ships, progress.XP, progress.Credits) == nil:
+			log.Infof("  [%d] %s: can unlock for %d XP and %d credits", def.ID, def.Name, def.Research.XP, def.Research.Credits)
+		default:
+			log.Infof("  [%d] %s: locked, %d XP and %d credits", def.ID, def.Name, def.Research.XP, def.Research.Credits)
+		}
+	}
+}
+
 func main() {
 	log.Info("Starting...")
 
@@ -32,31 +100,133 @@ func main() {
 		socket *web.Socket
 	)
 
-	if socket, err = web.Connect("ws://localhost:3000/ws?name=testuser"); err != nil {
-		log.Panicf("Error connecting to server: %v", err)
-	}
+	var (
+		address  *string        = flag.String("server", "ws://localhost:3000/ws", "Server WebSocket address")
+		name     *string        = flag.String("name", "testuser", "Player name, or account name with -password")
+		password *string        = flag.String("password", "", "Log in to the account called -name, leave empty to play as a guest")
+		register *bool          = flag.Bool("register", false, "Create the account first, with -name and -password")
+		ship     *int           = flag.Int("ship", int(definitions.SHIP_COLOSSUS), "Ship ID to spawn as")
+		unlock   *int           = flag.Int("unlock", -1, "Ship ID to unlock with the account's XP and credits before joining")
+		loadout  *string        = flag.String("loadout", "", "Squadron variant per carrier slot, comma separated (e.g. 0,0,2), left out slots keep their default")
+		room     *string        = flag.String("room", "default", "Room to join")
+		spectate *bool          = flag.Bool("spectate", false, "Join as a spectator")
+		delay    *time.Duration = flag.Duration("interp-delay", game.DEFAULT_INTERP_DELAY, "How far in the past other ships are rendered, larger values hide more packet jitter")
+		version  *uint          = flag.Uint("protocol", uint(protocol.PROTOCOL_VERSION), "Wire format version, for servers that only speak an older one")
+		record   *string        = flag.String("record", "", "Write everything the server sends to this demo file")
+		play     *string        = flag.String("play", "", "Play back a demo file instead of connecting")
+	)
 
-	defer socket.Close()
+	flag.Parse()
 
-	socket.OnClose = func() {
-		log.Error("Socket closed")
+	if *version < uint(protocol.PROTOCOL_MIN_VERSION) || *version > uint(protocol.PROTOCOL_VERSION) {
+		log.Panicf("Unsupported protocol version %d, this client speaks v%d to v%d", *version, protocol.PROTOCOL_MIN_VERSION, protocol.PROTOCOL_VERSION)
 	}
 
-	g.Socket = socket
+	g.Protocol = uint16(*version)
+	g.InterpolationDelay = *delay
 
-	go socket.InitiateUpdateLoop(func(message []byte) {
-		var (
-			reader      *protocol.Reader = protocol.NewReader(message)
-			messageType uint8            = reader.GetU8()
-		)
+	if *play != "" {
+		var d *demo.Demo
+		if d, err = demo.Load(*play); err != nil {
+			log.Panicf("Error loading demo: %v", err)
+		}
 
-		switch messageType {
-		case protocol.PACKET_CLIENTBOUND_VIEW_UPDATE:
-			g.ParseViewUpdate(reader)
-		default:
-			fmt.Printf("Unknown message type: %d\n", messageType)
+		if d.Header.Protocol < protocol.PROTOCOL_MIN_VERSION || d.Header.Protocol > protocol.PROTOCOL_VERSION {
+			log.Panicf("Demo uses protocol v%d, this client speaks v%d to v%d", d.Header.Protocol, protocol.PROTOCOL_MIN_VERSION, protocol.PROTOCOL_VERSION)
 		}
-	})
+
+		log.Infof("Playing %s: %s in room %q on %s, %s long", *play, d.Header.Name, d.Header.Room, d.Header.Start.Format(time.DateTime), d.Duration().Round(time.Second))
+
+		g.Protocol = d.Header.Protocol
+		g.Playback = game.NewPlayback(d)
+		g.Playback.OnMessage = func(messageType uint8, err error) {
+			report(g, messageType, err)
+		}
+	} else {
+		var picked []uint8
+		if !*spectate {
+			if picked, err = parseLoadout(definitions.ShipID(*ship), *loadout); err != nil {
+				log.Panicf("Invalid -loadout: %v", err)
+			}
+
+			if len(picked) > 0 && *version < uint(protocol.PROTOCOL_LOADOUT_VERSION) {
+				log.Panicf("Picking a loadout needs protocol v%d or later", protocol.PROTOCOL_LOADOUT_VERSION)
+			}
+		}
+
+		var token string
+		if *password != "" {
+			if *version < uint(protocol.PROTOCOL_ACCOUNTS_VERSION) {
+				log.Panicf("Logging in needs protocol v%d or later", protocol.PROTOCOL_ACCOUNTS_VERSION)
+			}
+
+			var login web.LoginResult
+			if login, err = web.Login(*address, *name, *password, *register); err != nil {
+				log.Panicf("Could not log in: %v", err)
+			}
+
+			log.Infof("Logged in as %s until %s", login.Name, login.Expires.Local().Format(time.DateTime))
+			token, *name = login.Token, login.Name
+
+			var progress web.Progress
+			if *unlock >= 0 {
+				progress, err = web.Unlock(*address, token, definitions.ShipID(*unlock))
+			} else {
+				progress, err = web.GetProgress(*address, token)
+			}
+
+			if err != nil {
+				log.Panicf("Could not load progress: %v", err)
+			}
+
+			logProgress(progress)
+		} else if *register || *unlock >= 0 {
+			log.Panic("-register and -unlock need a -password")
+		}
+
+		if socket, err = web.Connect(*address); err != nil {
+			log.Panicf("Error connecting to server: %v", err)
+		}
+
+		defer socket.Close()
+
+		socket.OnClose = func() {
+			log.Error("Socket closed")
+		}
+
+		g.Socket = socket
+		g.ServerAddress = *address
+
+		var shipID uint8 = uint8(*ship)
+		if *spectate {
+			shipID = protocol.JOIN_SHIP_SPECTATE
+		}
+
+		var recorder *demo.Recorder
+		if *record != "" {
+			if recorder, err = demo.Create(*record, demo.Header{Protocol: g.Protocol, Name: *name, Room: *room, ShipID: shipID}); err != nil {
+				log.Panicf("Error creating demo: %v", err)
+			}
+
+			log.Infof("Recording to %s", *record)
+			defer func() {
+				if err := recorder.Close(); err != nil {
+					log.Errorf("Demo recording failed: %v", err)
+				}
+			}()
+		}
+
+		g.SendJoin(*name, shipID, *room, token, picked)
+
+		go socket.InitiateUpdateLoop(func(message []byte) {
+			if recorder != nil {
+				recorder.Record(message)
+			}
+
+			messageType, err := g.HandleMessage(message)
+			report(g, messageType, err)
+		})
+	}
 
 	if err = ebiten.RunGame(g); err != nil {
 		log.Panicf("Error running game: %v", err)
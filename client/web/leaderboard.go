@@ -0,0 +1,43 @@
+package web
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type (
+	// One row of what the server's /leaderboard answers with
+	LeaderboardEntry struct {
+		Rank    int
+		Name    string
+		Value   float64
+		Matches int64
+	}
+
+	Leaderboard struct {
+		Metric, Window string
+		Since          time.Time // Zero for all time
+		Entries        []LeaderboardEntry
+	}
+)
+
+// The server's top players by a metric ("score", "kills", "damage", ...) over a window ("daily", "weekly" or
+// "all")
+func GetLeaderboard(address, metric, window string) (board Leaderboard, err error) {
+	var endpoint *url.URL
+	if endpoint, err = serverURL(address, "/leaderboard"); err != nil {
+		return
+	}
+
+	endpoint.RawQuery = url.Values{"metric": {metric}, "window": {window}}.Encode()
+
+	var client *http.Client = &http.Client{Timeout: LOGIN_TIMEOUT}
+	response, err := client.Get(endpoint.String())
+	if err != nil {
+		return
+	}
+
+	err = decodeResponse(response, &board)
+	return
+}
@@ -0,0 +1,86 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const LOGIN_TIMEOUT time.Duration = 10 * time.Second
+
+// What the server's /login and /register answer with
+type LoginResult struct {
+	Token   string
+	Name    string // As registered, which may differ in case from what was typed
+	Expires time.Time
+}
+
+// The HTTP address of an endpoint on the server behind a WebSocket address
+func serverURL(address, path string) (endpoint *url.URL, err error) {
+	if endpoint, err = url.Parse(address); err != nil {
+		return
+	}
+
+	switch endpoint.Scheme {
+	case "wss":
+		endpoint.Scheme = "https"
+	default:
+		endpoint.Scheme = "http"
+	}
+
+	endpoint.Path, endpoint.RawQuery = path, ""
+	return
+}
+
+// Decodes a 200 into out, anything else into the error the server gave
+func decodeResponse(response *http.Response, out any) error {
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		var failure struct{ Error string }
+		json.NewDecoder(response.Body).Decode(&failure)
+		if failure.Error == "" {
+			failure.Error = response.Status
+		}
+
+		return errors.New(failure.Error)
+	}
+
+	return json.NewDecoder(response.Body).Decode(out)
+}
+
+// POSTs body as JSON to an account endpoint and decodes the answer into out
+func postAccount(address, path string, body, out any) (err error) {
+	var endpoint *url.URL
+	if endpoint, err = serverURL(address, path); err != nil {
+		return
+	}
+
+	var data []byte
+	if data, err = json.Marshal(body); err != nil {
+		return
+	}
+
+	var client *http.Client = &http.Client{Timeout: LOGIN_TIMEOUT}
+	response, err := client.Post(endpoint.String(), "application/json", bytes.NewReader(data))
+	if err != nil {
+		return
+	}
+
+	return decodeResponse(response, out)
+}
+
+// Logs in, or registers first, on the server behind a WebSocket address and returns the session token to
+// join with
+func Login(address, name, password string, register bool) (result LoginResult, err error) {
+	var path string = "/login"
+	if register {
+		path = "/register"
+	}
+
+	err = postAccount(address, path, map[string]string{"Name": name, "Password": password}, &result)
+	return
+}
@@ -0,0 +1,21 @@
+package web
+
+import "github.com/z46-dev/game-dev-project/shared/definitions"
+
+// What the server's /progress and /unlock answer with
+type Progress struct {
+	XP, Credits int64
+	Ships       []definitions.ShipID // Unlocked ones, starter ships are always available
+}
+
+// Where the logged in account stands in the tech tree
+func GetProgress(address, token string) (progress Progress, err error) {
+	err = postAccount(address, "/progress", map[string]string{"Token": token}, &progress)
+	return
+}
+
+// Spends XP and credits on a ship, the server checks the tech tree
+func Unlock(address, token string, ship definitions.ShipID) (progress Progress, err error) {
+	err = postAccount(address, "/unlock", map[string]any{"Token": token, "Ship": ship}, &progress)
+	return
+}
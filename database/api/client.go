@@ -0,0 +1,192 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/z46-dev/game-dev-project/shared/definitions"
+)
+
+const CLIENT_TIMEOUT time.Duration = 5 * time.Second
+
+type Client struct {
+	BaseURL string // e.g. "http://localhost:3100"
+	Key     string
+	HTTP    *http.Client
+}
+
+func NewClient(baseURL, key string) (c *Client) {
+	c = &Client{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Key:     key,
+		HTTP:    &http.Client{Timeout: CLIENT_TIMEOUT},
+	}
+
+	return
+}
+
+// Sends body as JSON, if any, and decodes the answer into out, if any. Error responses come back as *Error.
+func (c *Client) do(method, path string, body, out any) (err error) {
+	var reader io.Reader
+	if body != nil {
+		var data []byte
+		if data, err = json.Marshal(body); err != nil {
+			return
+		}
+
+		reader = bytes.NewReader(data)
+	}
+
+	var request *http.Request
+	if request, err = http.NewRequest(method, c.BaseURL+path, reader); err != nil {
+		return
+	}
+
+	request.Header.Set("Authorization", "Bearer "+c.Key)
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	var response *http.Response
+	if response, err = c.HTTP.Do(request); err != nil {
+		return
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode >= 300 {
+		var failure ErrorResponse
+		json.NewDecoder(response.Body).Decode(&failure)
+		return &Error{Status: response.StatusCode, Message: failure.Error}
+	}
+
+	if out != nil {
+		err = json.NewDecoder(response.Body).Decode(out)
+	}
+
+	return
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("database service answered %d", e.Status)
+	}
+
+	return e.Message
+}
+
+// The Err* the status stands for, so callers can use errors.Is
+func (e *Error) Unwrap() error {
+	switch e.Status {
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusBadRequest:
+		return ErrInvalid
+	default:
+		return nil
+	}
+}
+
+func (c *Client) Health() (h Health, err error) {
+	err = c.do(http.MethodGet, "/health", nil, &h)
+	return
+}
+
+func (c *Client) Register(name, password string) (a Account, err error) {
+	err = c.do(http.MethodPost, "/accounts", &CredentialsRequest{Name: name, Password: password}, &a)
+	return
+}
+
+// Checks a password and starts a session, ErrUnauthorized if either the name or the password is wrong
+func (c *Client) Login(name, password string) (s Session, err error) {
+	err = c.do(http.MethodPost, "/sessions", &CredentialsRequest{Name: name, Password: password}, &s)
+	return
+}
+
+// The account a session token belongs to, ErrUnauthorized if it expired or was revoked
+func (c *Client) ValidateSession(token string) (a Account, err error) {
+	err = c.do(http.MethodPost, "/sessions/validate", &TokenRequest{Token: token}, &a)
+	return
+}
+
+func (c *Client) RevokeSession(token string) error {
+	return c.do(http.MethodPost, "/sessions/revoke", &TokenRequest{Token: token}, nil)
+}
+
+func (c *Client) Account(id uint64) (a Account, err error) {
+	err = c.do(http.MethodGet, fmt.Sprintf("/accounts/%d", id), nil, &a)
+	return
+}
+
+func (c *Client) AccountByName(name string) (a Account, err error) {
+	err = c.do(http.MethodGet, "/accounts?name="+url.QueryEscape(name), nil, &a)
+	return
+}
+
+func (c *Client) Stats(id uint64) (s Stats, err error) {
+	err = c.do(http.MethodGet, fmt.Sprintf("/accounts/%d/stats", id), nil, &s)
+	return
+}
+
+func (c *Client) Ships(id uint64) (ships []definitions.ShipID, err error) {
+	err = c.do(http.MethodGet, fmt.Sprintf("/accounts/%d/ships", id), nil, &ships)
+	return
+}
+
+// Unlocks a ship for the account, paying for it from the wallet. ErrInvalid if the tech tree does not allow it
+// yet or the account cannot afford it.
+func (c *Client) Unlock(id uint64, ship definitions.ShipID) (progress Progress, err error) {
+	err = c.do(http.MethodPost, fmt.Sprintf("/accounts/%d/ships", id), &UnlockRequest{Ship: ship}, &progress)
+	return
+}
+
+func (c *Client) Progress(id uint64) (progress Progress, err error) {
+	err = c.do(http.MethodGet, fmt.Sprintf("/accounts/%d/progress", id), nil, &progress)
+	return
+}
+
+// Adds what the account earned to its wallet
+func (c *Client) Award(id uint64, earned Wallet) (progress Progress, err error) {
+	err = c.do(http.MethodPost, fmt.Sprintf("/accounts/%d/wallet", id), &earned, &progress)
+	return
+}
+
+// The top accounts by a metric over a window, see LeaderboardMetrics and ParseWindow. A limit of 0 takes the
+// service's default.
+func (c *Client) Leaderboard(metric, window string, limit int) (board Leaderboard, err error) {
+	var query url.Values = url.Values{"metric": {metric}, "window": {window}}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	err = c.do(http.MethodGet, "/leaderboard?"+query.Encode(), nil, &board)
+	return
+}
+
+// Stores a finished match and adds each account's line to its stats, returning the record with its ID
+func (c *Client) RecordMatch(m *MatchRecord) (recorded MatchRecord, err error) {
+	err = c.do(http.MethodPost, "/matches", m, &recorded)
+	return
+}
+
+func (c *Client) Match(id uint64) (m MatchRecord, err error) {
+	err = c.do(http.MethodGet, fmt.Sprintf("/matches/%d", id), nil, &m)
+	return
+}
+
+// The account's most recent matches, newest first
+func (c *Client) Matches(id uint64, limit int) (matches []MatchRecord, err error) {
+	err = c.do(http.MethodGet, fmt.Sprintf("/accounts/%d/matches?limit=%d", id, limit), nil, &matches)
+	return
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+const (
+	WINDOW_DAILY  string = "daily"
+	WINDOW_WEEKLY string = "weekly"
+	WINDOW_ALL    string = "all"
+)
+
+// What a leaderboard can rank by, read off an account's totals
+var LeaderboardMetrics map[string]func(s *Stats) float64 = map[string]func(s *Stats) float64{
+	"score":            func(s *Stats) float64 { return float64(s.Score) },
+	"kills":            func(s *Stats) float64 { return float64(s.Kills) },
+	"damage":           func(s *Stats) float64 { return s.Damage },
+	"captures":         func(s *Stats) float64 { return float64(s.Captures) },
+	"planes_shot_down": func(s *Stats) float64 { return float64(s.PlanesShotDown) },
+	"wins":             func(s *Stats) float64 { return float64(s.Wins) },
+	"time_alive":       func(s *Stats) float64 { return float64(s.TimeAlive) },
+}
+
+// Metric names in a stable order, for listing them
+func MetricNames() (names []string) {
+	for name := range LeaderboardMetrics {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+	return
+}
+
+// How far back a window reaches: "daily", "weekly", "all" (also the default) or any Go duration such as "72h".
+// Zero means all time.
+func ParseWindow(window string) (span time.Duration, err error) {
+	switch window {
+	case WINDOW_DAILY:
+		return 24 * time.Hour, nil
+	case WINDOW_WEEKLY:
+		return 7 * 24 * time.Hour, nil
+	case WINDOW_ALL, "":
+		return 0, nil
+	}
+
+	if span, err = time.ParseDuration(window); err != nil || span <= 0 {
+		return 0, fmt.Errorf("%w: window is %s, %s, %s or a positive duration such as 72h", ErrInvalid, WINDOW_DAILY, WINDOW_WEEKLY, WINDOW_ALL)
+	}
+
+	return
+}
@@ -0,0 +1,137 @@
+// Package api is what the database service stores and serves, and the client the game server reaches it with.
+// Every request carries the shared key as "Authorization: Bearer <key>".
+package api
+
+import (
+	"errors"
+	"time"
+
+	"github.com/z46-dev/game-dev-project/shared/definitions"
+)
+
+var (
+	ErrNotFound     error = errors.New("not found")
+	ErrConflict     error = errors.New("already exists")
+	ErrUnauthorized error = errors.New("unauthorized")
+	ErrInvalid      error = errors.New("invalid request")
+)
+
+type (
+	Account struct {
+		ID      uint64
+		Name    string // Unique, ignoring case
+		Created time.Time
+	}
+
+	// Lifetime totals of an account, added to by every match it is recorded in
+	Stats struct {
+		Matches, Wins  int64
+		Kills, Deaths  int64
+		PlanesShotDown int64
+		Captures       int64
+		Damage         float64
+		TimeAlive      int64 // Seconds
+		Score          int64
+		LastMatch      time.Time
+	}
+
+	// One player's line in a match
+	MatchPlayer struct {
+		AccountID      uint64 // 0 for guests, who are kept in the record but have no stats
+		Name           string
+		Ship           definitions.ShipID
+		Won            bool
+		Kills, Deaths  int64
+		PlanesShotDown int64
+		Captures       int64
+		Damage         float64
+		TimeAlive      int64 // Seconds
+		Score          int64
+	}
+
+	MatchRecord struct {
+		ID             uint64 // Assigned when recorded
+		Room           string
+		Started, Ended time.Time
+		Players        []MatchPlayer
+	}
+
+	// One row of a leaderboard
+	LeaderboardEntry struct {
+		Rank    int
+		Account uint64
+		Name    string
+		Value   float64 // Of the metric the board ranks by
+		Matches int64   // Played inside the window
+	}
+
+	Leaderboard struct {
+		Metric, Window string
+		Since          time.Time `json:",omitzero"` // Start of the window, zero for all time
+		Entries        []LeaderboardEntry
+	}
+
+	Health struct {
+		Schema int // Version of the store's schema
+	}
+
+	// Registration and login both take a name and password
+	CredentialsRequest struct {
+		Name, Password string
+	}
+
+	// A login. The token goes in the join packet, the game server checks it here.
+	Session struct {
+		Token   string
+		Account Account
+		Expires time.Time
+	}
+
+	TokenRequest struct {
+		Token string
+	}
+
+	// Experience and currency, as a balance or as an amount awarded
+	Wallet struct {
+		XP, Credits int64
+	}
+
+	// Where an account is in the tech tree
+	Progress struct {
+		Wallet
+		Ships []definitions.ShipID // Unlocked, in ID order. Starter ships are open to everyone and not listed.
+	}
+
+	// Unlocking spends the ship's research cost from the wallet
+	UnlockRequest struct {
+		Ship definitions.ShipID
+	}
+
+	// Body of every response that is not a 2xx
+	ErrorResponse struct {
+		Error string
+	}
+
+	// A non 2xx response as the client returns it
+	Error struct {
+		Status  int
+		Message string
+	}
+)
+
+// Adds a match line to the totals
+func (s *Stats) Add(p *MatchPlayer, ended time.Time) {
+	s.Matches++
+	if p.Won {
+		s.Wins++
+	}
+
+	s.Kills += p.Kills
+	s.Deaths += p.Deaths
+	s.PlanesShotDown += p.PlanesShotDown
+	s.Captures += p.Captures
+	s.Damage += p.Damage
+	s.TimeAlive += p.TimeAlive
+	s.Score += p.Score
+	s.LastMatch = ended
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"github.com/creasty/defaults"
+	"github.com/go-playground/validator/v10"
+)
+
+type Configuration struct {
+	WebServer struct {
+		Address string `toml:"address" default:"127.0.0.1:3100" validate:"required"` // Listen address for the API, keep it off public interfaces
+	} `toml:"web_server"` // Web server configuration
+	Store struct {
+		Path        string `toml:"path" default:"game.db" validate:"required"` // Database file, created along with any missing schema on start
+		SessionDays int    `toml:"session_days" default:"30" validate:"gte=1"` // How long a login stays valid
+	} `toml:"store"` // On-disk storage
+	API struct {
+		Key string `toml:"key" validate:"required,min=16"` // Shared secret the game server sends as a bearer token
+	} `toml:"api"` // Access to the API
+}
+
+var (
+	Config           Configuration
+	loadedConfigPath string
+)
+
+func LoadedConfigPath() string {
+	return loadedConfigPath
+}
+
+func loadConfig(path string) (err error) {
+	// Apply struct defaults BEFORE loading TOML (so TOML overrides)
+	if err = defaults.Set(&Config); err != nil {
+		err = fmt.Errorf("set defaults: %w", err)
+		return
+	}
+
+	// Decode TOML file into struct
+	if _, err = toml.DecodeFile(path, &Config); err != nil {
+		err = fmt.Errorf("decode toml: %w", err)
+		return
+	}
+
+	// Validate required fields
+	if err = validator.New(validator.WithRequiredStructEnabled()).Struct(Config); err != nil {
+		err = fmt.Errorf("validate config: %w", err)
+	}
+
+	return
+}
+
+// generateDefaultConfig writes a database_server.toml with all default values filled in.
+// It will overwrite any existing file at path.
+func generateDefaultConfig(path string) (err error) {
+	var cfg Configuration
+
+	// 1. Apply struct defaults
+	if err = defaults.Set(&cfg); err != nil {
+		err = fmt.Errorf("set defaults: %w", err)
+		return
+	}
+
+	// NOTE: Do NOT validate here.
+	// The default config is allowed to be "invalid" from a required-fields POV;
+	// it's just a template for the user to fill in.
+	// Validation happens in LoadConfig() when we actually load the file.
+
+	// 2. Create / truncate the file
+	var file *os.File
+	if file, err = os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644); err != nil {
+		err = fmt.Errorf("create config file: %w", err)
+		return
+	}
+
+	defer file.Close()
+
+	// 3. Encode as TOML
+	var encoder *toml.Encoder = toml.NewEncoder(file)
+	encoder.Indent = "    "
+	if err = encoder.Encode(cfg); err != nil {
+		err = fmt.Errorf("encode toml: %w", err)
+	}
+
+	return
+}
+
+func Init(path string) (err error) {
+	if !filepath.IsAbs(path) {
+		if path, err = filepath.Abs(path); err != nil {
+			return err
+		}
+	}
+	loadedConfigPath = path
+
+	if _, err = os.Stat(path); err != nil {
+		if err = generateDefaultConfig(path); err != nil {
+			return
+		}
+
+		err = fmt.Errorf("no config file found, created a default config at %s. Please fill in the required values and try again", path)
+		return
+	}
+
+	if err = loadConfig(path); err != nil {
+		return err
+	}
+
+	return nil
+}
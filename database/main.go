@@ -1,5 +1,58 @@
+// The database service: accounts, stats, wallets, unlocked ships and match records in an embedded on-disk store,
+// served over an HTTP API only the game server holds the key to.
+//
+//	go run ./database
 package main
 
+import (
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/z46-dev/game-dev-project/database/config"
+	"github.com/z46-dev/game-dev-project/database/store"
+	"github.com/z46-dev/golog"
+)
+
+var (
+	log *golog.Logger = golog.New().Prefix("[DATABASE]", golog.BoldBlue).Timestamp()
+	db  *store.Store
+)
+
+// Closes the store on Ctrl+C or SIGTERM, so the file is left consistent and unlocked
+func closeOnSignal() {
+	var signals chan os.Signal = make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	log.Infof("Shutting down (%s)", <-signals)
+
+	if err := db.Close(); err != nil {
+		log.Errorf("Could not close the store: %v", err)
+		os.Exit(1)
+	}
+
+	os.Exit(0)
+}
+
 func main() {
-	panic("Not implemented")
-}
\ No newline at end of file
+	var err error
+	if err = config.Init("database_server.toml"); err != nil {
+		log.Panicf("Could not load configuration: %v", err)
+		return
+	}
+
+	if db, err = store.Open(config.Config.Store.Path); err != nil {
+		log.Panicf("Could not open %s: %v", config.Config.Store.Path, err)
+		return
+	}
+
+	db.SessionLifetime = time.Duration(config.Config.Store.SessionDays) * 24 * time.Hour
+	log.Infof("Opened %s at schema v%d", config.Config.Store.Path, db.Schema())
+	go closeOnSignal()
+
+	log.Infof("Serving the API on %s", config.Config.WebServer.Address)
+	if err = http.ListenAndServe(config.Config.WebServer.Address, authenticate(routes())); err != nil {
+		log.Panicf("Server stopped: %v", err)
+	}
+}
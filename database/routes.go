@@ -0,0 +1,289 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/z46-dev/game-dev-project/database/api"
+	"github.com/z46-dev/game-dev-project/database/config"
+)
+
+const MAX_REQUEST_BODY int64 = 1 << 20
+
+func routes() (mux *http.ServeMux) {
+	mux = http.NewServeMux()
+	mux.HandleFunc("GET /health", handleHealth)
+	mux.HandleFunc("POST /accounts", handleCreateAccount)
+	mux.HandleFunc("POST /sessions", handleLogin)
+	mux.HandleFunc("POST /sessions/validate", handleValidateSession)
+	mux.HandleFunc("POST /sessions/revoke", handleRevokeSession)
+	mux.HandleFunc("GET /accounts", handleAccountByName)
+	mux.HandleFunc("GET /accounts/{id}", handleAccount)
+	mux.HandleFunc("GET /accounts/{id}/stats", handleStats)
+	mux.HandleFunc("GET /accounts/{id}/ships", handleShips)
+	mux.HandleFunc("POST /accounts/{id}/ships", handleUnlock)
+	mux.HandleFunc("GET /accounts/{id}/progress", handleProgress)
+	mux.HandleFunc("POST /accounts/{id}/wallet", handleAward)
+	mux.HandleFunc("GET /accounts/{id}/matches", handleMatches)
+	mux.HandleFunc("POST /matches", handleRecordMatch)
+	mux.HandleFunc("GET /matches/{id}", handleMatch)
+	mux.HandleFunc("GET /leaderboard", handleLeaderboard)
+	return
+}
+
+// Rejects requests without the shared key before they reach a handler. That is a 403, 401 means a player's
+// name, password or session was wrong.
+func authenticate(next http.Handler) http.Handler {
+	var expected []byte = []byte("Bearer " + config.Config.API.Key)
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(request.Header.Get("Authorization")), expected) != 1 {
+			writeJSON(writer, http.StatusForbidden, &api.ErrorResponse{Error: "missing or wrong API key"})
+			return
+		}
+
+		next.ServeHTTP(writer, request)
+	})
+}
+
+func writeJSON(writer http.ResponseWriter, status int, value any) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	json.NewEncoder(writer).Encode(value)
+}
+
+// Maps store errors to statuses, anything unexpected is logged and hidden behind a 500
+func writeError(writer http.ResponseWriter, err error) {
+	var status int
+	switch {
+	case errors.Is(err, api.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, api.ErrConflict):
+		status = http.StatusConflict
+	case errors.Is(err, api.ErrUnauthorized):
+		status = http.StatusUnauthorized
+	case errors.Is(err, api.ErrInvalid):
+		status = http.StatusBadRequest
+	default:
+		log.Errorf("Request failed: %v", err)
+		writeJSON(writer, http.StatusInternalServerError, &api.ErrorResponse{Error: "internal error"})
+		return
+	}
+
+	writeJSON(writer, status, &api.ErrorResponse{Error: err.Error()})
+}
+
+func readJSON(writer http.ResponseWriter, request *http.Request, out any) (err error) {
+	if err = json.NewDecoder(http.MaxBytesReader(writer, request.Body, MAX_REQUEST_BODY)).Decode(out); err != nil {
+		err = fmt.Errorf("%w: %v", api.ErrInvalid, err)
+	}
+
+	return
+}
+
+func pathID(request *http.Request) (id uint64, err error) {
+	if id, err = strconv.ParseUint(request.PathValue("id"), 10, 64); err != nil {
+		err = fmt.Errorf("%w: %v", api.ErrInvalid, err)
+	}
+
+	return
+}
+
+// Writes what a store call returned, or its error
+func respond[T any](writer http.ResponseWriter, status int, value T, err error) {
+	if err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	writeJSON(writer, status, value)
+}
+
+func handleHealth(writer http.ResponseWriter, request *http.Request) {
+	writeJSON(writer, http.StatusOK, &api.Health{Schema: db.Schema()})
+}
+
+func handleCreateAccount(writer http.ResponseWriter, request *http.Request) {
+	var body api.CredentialsRequest
+	if err := readJSON(writer, request, &body); err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	account, err := db.CreateAccount(body.Name, body.Password)
+	respond(writer, http.StatusCreated, account, err)
+}
+
+func handleLogin(writer http.ResponseWriter, request *http.Request) {
+	var body api.CredentialsRequest
+	if err := readJSON(writer, request, &body); err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	session, err := db.Login(body.Name, body.Password)
+	respond(writer, http.StatusCreated, session, err)
+}
+
+func handleValidateSession(writer http.ResponseWriter, request *http.Request) {
+	var body api.TokenRequest
+	if err := readJSON(writer, request, &body); err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	account, err := db.ValidateSession(body.Token)
+	respond(writer, http.StatusOK, account, err)
+}
+
+func handleRevokeSession(writer http.ResponseWriter, request *http.Request) {
+	var body api.TokenRequest
+	if err := readJSON(writer, request, &body); err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	if err := db.RevokeSession(body.Token); err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func handleAccountByName(writer http.ResponseWriter, request *http.Request) {
+	var name string = strings.TrimSpace(request.URL.Query().Get("name"))
+	if name == "" {
+		writeError(writer, fmt.Errorf("%w: name is required", api.ErrInvalid))
+		return
+	}
+
+	account, err := db.AccountByName(name)
+	respond(writer, http.StatusOK, account, err)
+}
+
+func handleAccount(writer http.ResponseWriter, request *http.Request) {
+	id, err := pathID(request)
+	if err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	account, err := db.Account(id)
+	respond(writer, http.StatusOK, account, err)
+}
+
+func handleStats(writer http.ResponseWriter, request *http.Request) {
+	id, err := pathID(request)
+	if err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	stats, err := db.Stats(id)
+	respond(writer, http.StatusOK, stats, err)
+}
+
+func handleShips(writer http.ResponseWriter, request *http.Request) {
+	id, err := pathID(request)
+	if err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	ships, err := db.Ships(id)
+	respond(writer, http.StatusOK, ships, err)
+}
+
+func handleUnlock(writer http.ResponseWriter, request *http.Request) {
+	var body api.UnlockRequest
+	id, err := pathID(request)
+	if err == nil {
+		err = readJSON(writer, request, &body)
+	}
+
+	if err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	progress, err := db.Unlock(id, body.Ship)
+	respond(writer, http.StatusOK, progress, err)
+}
+
+func handleProgress(writer http.ResponseWriter, request *http.Request) {
+	id, err := pathID(request)
+	if err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	progress, err := db.Progress(id)
+	respond(writer, http.StatusOK, progress, err)
+}
+
+func handleAward(writer http.ResponseWriter, request *http.Request) {
+	var body api.Wallet
+	id, err := pathID(request)
+	if err == nil {
+		err = readJSON(writer, request, &body)
+	}
+
+	if err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	progress, err := db.Award(id, body)
+	respond(writer, http.StatusOK, progress, err)
+}
+
+func handleMatches(writer http.ResponseWriter, request *http.Request) {
+	id, err := pathID(request)
+	if err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	// Missing or unparsable limits fall back to the default
+	limit, _ := strconv.Atoi(request.URL.Query().Get("limit"))
+	matches, err := db.Matches(id, limit)
+	respond(writer, http.StatusOK, matches, err)
+}
+
+func handleRecordMatch(writer http.ResponseWriter, request *http.Request) {
+	var body api.MatchRecord
+	if err := readJSON(writer, request, &body); err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	recorded, err := db.RecordMatch(body)
+	respond(writer, http.StatusCreated, recorded, err)
+}
+
+func handleMatch(writer http.ResponseWriter, request *http.Request) {
+	id, err := pathID(request)
+	if err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	match, err := db.Match(id)
+	respond(writer, http.StatusOK, match, err)
+}
+
+// GET /leaderboard?metric=kills&window=weekly&limit=10
+func handleLeaderboard(writer http.ResponseWriter, request *http.Request) {
+	var query url.Values = request.URL.Query()
+
+	// Missing or unparsable limits fall back to the default
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	board, err := db.Leaderboard(query.Get("metric"), query.Get("window"), limit)
+	respond(writer, http.StatusOK, board, err)
+}
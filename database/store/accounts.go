@@ -0,0 +1,128 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/z46-dev/game-dev-project/database/api"
+	"github.com/z46-dev/game-dev-project/shared/definitions"
+	"github.com/z46-dev/game-dev-project/shared/names"
+	bolt "go.etcd.io/bbolt"
+)
+
+func nameKey(name string) []byte {
+	return []byte(strings.ToLower(strings.TrimSpace(name)))
+}
+
+// Registers a name with a password. The name must pass names.Validate and be free, ignoring case.
+func (s *Store) CreateAccount(name, password string) (a api.Account, err error) {
+	if err = names.Validate(name); err != nil {
+		return a, fmt.Errorf("%w: %v", api.ErrInvalid, err)
+	}
+
+	if err = validatePassword(password); err != nil {
+		return
+	}
+
+	// Hashing is slow on purpose, so it happens before the write lock is taken
+	var c credential
+	if c, err = newCredential(password); err != nil {
+		return
+	}
+
+	err = s.db.Update(func(tx *bolt.Tx) (err error) {
+		var taken *bolt.Bucket = tx.Bucket(BUCKET_ACCOUNT_NAMES)
+		if taken.Get(nameKey(name)) != nil {
+			return fmt.Errorf("%w: the name %q is taken", api.ErrConflict, name)
+		}
+
+		var accounts *bolt.Bucket = tx.Bucket(BUCKET_ACCOUNTS)
+		if a.ID, err = accounts.NextSequence(); err != nil {
+			return
+		}
+
+		a.Name, a.Created = name, time.Now().UTC()
+		if err = put(accounts, itob(a.ID), &a); err != nil {
+			return
+		}
+
+		if err = put(tx.Bucket(BUCKET_CREDENTIALS), itob(a.ID), &c); err != nil {
+			return
+		}
+
+		return taken.Put(nameKey(name), itob(a.ID))
+	})
+
+	return
+}
+
+func (s *Store) Account(id uint64) (a api.Account, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		return get(tx.Bucket(BUCKET_ACCOUNTS), itob(id), &a)
+	})
+
+	return
+}
+
+// Looks an account up by name, ignoring case
+func (s *Store) AccountByName(name string) (a api.Account, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		var id []byte = tx.Bucket(BUCKET_ACCOUNT_NAMES).Get(nameKey(name))
+		if id == nil {
+			return api.ErrNotFound
+		}
+
+		return get(tx.Bucket(BUCKET_ACCOUNTS), id, &a)
+	})
+
+	return
+}
+
+func exists(tx *bolt.Tx, id uint64) error {
+	if tx.Bucket(BUCKET_ACCOUNTS).Get(itob(id)) == nil {
+		return fmt.Errorf("%w: account %d", api.ErrNotFound, id)
+	}
+
+	return nil
+}
+
+// Zero for an account that has not played yet
+func (s *Store) Stats(id uint64) (stats api.Stats, err error) {
+	err = s.db.View(func(tx *bolt.Tx) (err error) {
+		if err = exists(tx, id); err != nil {
+			return
+		}
+
+		if err = get(tx.Bucket(BUCKET_STATS), itob(id), &stats); errors.Is(err, api.ErrNotFound) {
+			err = nil
+		}
+
+		return
+	})
+
+	return
+}
+
+func unlocks(tx *bolt.Tx, id uint64) (ships []definitions.ShipID, err error) {
+	if err = exists(tx, id); err != nil {
+		return
+	}
+
+	if err = get(tx.Bucket(BUCKET_UNLOCKS), itob(id), &ships); errors.Is(err, api.ErrNotFound) {
+		ships, err = make([]definitions.ShipID, 0), nil
+	}
+
+	return
+}
+
+// Ships the account has unlocked, in ID order
+func (s *Store) Ships(id uint64) (ships []definitions.ShipID, err error) {
+	err = s.db.View(func(tx *bolt.Tx) (err error) {
+		ships, err = unlocks(tx, id)
+		return
+	})
+
+	return
+}
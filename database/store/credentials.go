@@ -0,0 +1,159 @@
+package store
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/z46-dev/game-dev-project/database/api"
+	bolt "go.etcd.io/bbolt"
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	MIN_PASSWORD_LENGTH      int           = 8
+	MAX_PASSWORD_LENGTH      int           = 128
+	SALT_LENGTH              int           = 16
+	TOKEN_LENGTH             int           = 32
+	DEFAULT_SESSION_LIFETIME time.Duration = 30 * 24 * time.Hour
+
+	// Argon2id cost for new passwords, stored with each hash so raising it leaves older ones readable
+	ARGON2_TIME       uint32 = 2
+	ARGON2_MEMORY     uint32 = 19 * 1024 // KiB
+	ARGON2_THREADS    uint8  = 1
+	ARGON2_KEY_LENGTH uint32 = 32
+)
+
+type (
+	credential struct {
+		Salt, Hash   []byte
+		Time, Memory uint32
+		Threads      uint8
+	}
+
+	session struct {
+		Account          uint64
+		Created, Expires time.Time
+	}
+)
+
+func newCredential(password string) (c credential, err error) {
+	c = credential{Salt: make([]byte, SALT_LENGTH), Time: ARGON2_TIME, Memory: ARGON2_MEMORY, Threads: ARGON2_THREADS}
+	if _, err = rand.Read(c.Salt); err != nil {
+		return
+	}
+
+	c.Hash = c.hash(password)
+	return
+}
+
+func (c *credential) hash(password string) []byte {
+	return argon2.IDKey([]byte(password), c.Salt, c.Time, c.Memory, c.Threads, ARGON2_KEY_LENGTH)
+}
+
+// Hashed even when there is no account, so a wrong name takes as long as a wrong password
+var decoy credential = credential{Salt: make([]byte, SALT_LENGTH), Hash: make([]byte, ARGON2_KEY_LENGTH), Time: ARGON2_TIME, Memory: ARGON2_MEMORY, Threads: ARGON2_THREADS}
+
+func validatePassword(password string) error {
+	if len(password) < MIN_PASSWORD_LENGTH || len(password) > MAX_PASSWORD_LENGTH {
+		return fmt.Errorf("%w: passwords are %d to %d bytes", api.ErrInvalid, MIN_PASSWORD_LENGTH, MAX_PASSWORD_LENGTH)
+	}
+
+	return nil
+}
+
+// Sessions are stored under a hash of the token, so a copy of the file does not hand out logins
+func sessionKey(token string) []byte {
+	var sum [sha256.Size]byte = sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+// Checks the password and starts a session, expired ones are cleared out on the way
+func (s *Store) Login(name, password string) (result api.Session, err error) {
+	var (
+		account api.Account
+		stored  credential
+		found   bool
+	)
+
+	if err = s.db.View(func(tx *bolt.Tx) error {
+		var id []byte = tx.Bucket(BUCKET_ACCOUNT_NAMES).Get(nameKey(name))
+		if id == nil {
+			return nil
+		}
+
+		// Accounts from before passwords existed have no credential and cannot log in
+		if get(tx.Bucket(BUCKET_CREDENTIALS), id, &stored) != nil {
+			return nil
+		}
+
+		found = true
+		return get(tx.Bucket(BUCKET_ACCOUNTS), id, &account)
+	}); err != nil {
+		return
+	}
+
+	if !found {
+		stored = decoy
+	}
+
+	if subtle.ConstantTimeCompare(stored.hash(password), stored.Hash) != 1 || !found {
+		return result, fmt.Errorf("%w: wrong name or password", api.ErrUnauthorized)
+	}
+
+	var token []byte = make([]byte, TOKEN_LENGTH)
+	if _, err = rand.Read(token); err != nil {
+		return
+	}
+
+	var now time.Time = time.Now().UTC()
+	result = api.Session{Token: base64.RawURLEncoding.EncodeToString(token), Account: account, Expires: now.Add(s.SessionLifetime)}
+
+	err = s.db.Update(func(tx *bolt.Tx) (err error) {
+		var sessions *bolt.Bucket = tx.Bucket(BUCKET_SESSIONS)
+		var expired [][]byte
+		sessions.ForEach(func(key, value []byte) error {
+			var existing session
+			if json.Unmarshal(value, &existing) == nil && now.After(existing.Expires) {
+				expired = append(expired, key)
+			}
+
+			return nil
+		})
+
+		for _, key := range expired {
+			if err = sessions.Delete(key); err != nil {
+				return
+			}
+		}
+
+		return put(sessions, sessionKey(result.Token), &session{Account: account.ID, Created: now, Expires: result.Expires})
+	})
+
+	return
+}
+
+// The account a token was issued to, ErrUnauthorized once it has expired or been revoked
+func (s *Store) ValidateSession(token string) (account api.Account, err error) {
+	err = s.db.View(func(tx *bolt.Tx) (err error) {
+		var existing session
+		if get(tx.Bucket(BUCKET_SESSIONS), sessionKey(token), &existing) != nil || time.Now().After(existing.Expires) {
+			return fmt.Errorf("%w: session expired or unknown", api.ErrUnauthorized)
+		}
+
+		return get(tx.Bucket(BUCKET_ACCOUNTS), itob(existing.Account), &account)
+	})
+
+	return
+}
+
+// Ends a session, doing nothing if it already has
+func (s *Store) RevokeSession(token string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(BUCKET_SESSIONS).Delete(sessionKey(token))
+	})
+}
@@ -0,0 +1,120 @@
+package store
+
+import (
+	"cmp"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/z46-dev/game-dev-project/database/api"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	DEFAULT_LEADERBOARD_SIZE int = 10
+	MAX_LEADERBOARD_SIZE     int = 100
+)
+
+// Totals per account over the window. All time reads the running totals, shorter windows add up the matches
+// that ended inside them.
+func windowTotals(tx *bolt.Tx, since time.Time) (totals map[uint64]*api.Stats, err error) {
+	totals = make(map[uint64]*api.Stats)
+	if since.IsZero() {
+		err = tx.Bucket(BUCKET_STATS).ForEach(func(key, value []byte) (err error) {
+			var stats *api.Stats = &api.Stats{}
+			if err = json.Unmarshal(value, stats); err == nil {
+				totals[binary.BigEndian.Uint64(key)] = stats
+			}
+
+			return
+		})
+
+		return
+	}
+
+	var (
+		cursor  *bolt.Cursor = tx.Bucket(BUCKET_MATCHES_BY_END).Cursor()
+		matches *bolt.Bucket = tx.Bucket(BUCKET_MATCHES)
+	)
+
+	for key, _ := cursor.Seek(endKey(since, 0)); key != nil; key, _ = cursor.Next() {
+		var m api.MatchRecord
+		if err = get(matches, key[8:], &m); err != nil {
+			return
+		}
+
+		for i := range m.Players {
+			var player *api.MatchPlayer = &m.Players[i]
+			if player.AccountID == 0 {
+				continue
+			}
+
+			if totals[player.AccountID] == nil {
+				totals[player.AccountID] = &api.Stats{}
+			}
+
+			totals[player.AccountID].Add(player, m.Ended)
+		}
+	}
+
+	return
+}
+
+// The top accounts by a metric (see api.LeaderboardMetrics) over a window (see api.ParseWindow). Accounts
+// with nothing to show for the metric are left off.
+func (s *Store) Leaderboard(metric, window string, limit int) (board api.Leaderboard, err error) {
+	value, found := api.LeaderboardMetrics[metric]
+	if !found {
+		return board, fmt.Errorf("%w: metric is one of %s", api.ErrInvalid, strings.Join(api.MetricNames(), ", "))
+	}
+
+	var span time.Duration
+	if span, err = api.ParseWindow(window); err != nil {
+		return
+	}
+
+	if limit <= 0 {
+		limit = DEFAULT_LEADERBOARD_SIZE
+	}
+
+	limit = min(limit, MAX_LEADERBOARD_SIZE)
+	board = api.Leaderboard{Metric: metric, Window: cmp.Or(window, api.WINDOW_ALL), Entries: make([]api.LeaderboardEntry, 0)}
+	if span > 0 {
+		board.Since = time.Now().UTC().Add(-span)
+	}
+
+	err = s.db.View(func(tx *bolt.Tx) (err error) {
+		var totals map[uint64]*api.Stats
+		if totals, err = windowTotals(tx, board.Since); err != nil {
+			return
+		}
+
+		for id, stats := range totals {
+			if v := value(stats); v > 0 {
+				board.Entries = append(board.Entries, api.LeaderboardEntry{Account: id, Value: v, Matches: stats.Matches})
+			}
+		}
+
+		// Ties go to the older account
+		slices.SortFunc(board.Entries, func(a, b api.LeaderboardEntry) int {
+			return cmp.Or(cmp.Compare(b.Value, a.Value), cmp.Compare(a.Account, b.Account))
+		})
+
+		board.Entries = board.Entries[:min(len(board.Entries), limit)]
+		for i := range board.Entries {
+			var account api.Account
+			if err = get(tx.Bucket(BUCKET_ACCOUNTS), itob(board.Entries[i].Account), &account); err != nil {
+				return
+			}
+
+			board.Entries[i].Rank, board.Entries[i].Name = i+1, account.Name
+		}
+
+		return
+	})
+
+	return
+}
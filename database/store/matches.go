@@ -0,0 +1,136 @@
+package store
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/z46-dev/game-dev-project/database/api"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	DEFAULT_MATCH_HISTORY int = 20
+	MAX_MATCH_HISTORY     int = 200
+)
+
+func accountMatchKey(account, match uint64) []byte {
+	return binary.BigEndian.AppendUint64(itob(account), match)
+}
+
+// Sorts by end time, matches from before 1970 are clamped to it
+func endKey(ended time.Time, match uint64) []byte {
+	return binary.BigEndian.AppendUint64(itob(uint64(max(0, ended.UnixNano()))), match)
+}
+
+// Stores a finished match and adds every account's line to its stats in the same transaction, so stats
+// always agree with the history
+func (s *Store) RecordMatch(m api.MatchRecord) (recorded api.MatchRecord, err error) {
+	if m.Ended.IsZero() {
+		m.Ended = time.Now().UTC()
+	}
+
+	err = s.db.Update(func(tx *bolt.Tx) (err error) {
+		var matches *bolt.Bucket = tx.Bucket(BUCKET_MATCHES)
+		if m.ID, err = matches.NextSequence(); err != nil {
+			return
+		}
+
+		var (
+			stats   *bolt.Bucket    = tx.Bucket(BUCKET_STATS)
+			index   *bolt.Bucket    = tx.Bucket(BUCKET_ACCOUNT_MATCHES)
+			counted map[uint64]bool = make(map[uint64]bool)
+		)
+
+		for i := range m.Players {
+			var player *api.MatchPlayer = &m.Players[i]
+			if player.AccountID == 0 {
+				continue
+			}
+
+			if counted[player.AccountID] {
+				return fmt.Errorf("%w: account %d is in the match twice", api.ErrInvalid, player.AccountID)
+			}
+
+			counted[player.AccountID] = true
+			if err = exists(tx, player.AccountID); err != nil {
+				return
+			}
+
+			var total api.Stats
+			if err = get(stats, itob(player.AccountID), &total); err != nil && !errors.Is(err, api.ErrNotFound) {
+				return
+			}
+
+			total.Add(player, m.Ended)
+			if err = put(stats, itob(player.AccountID), &total); err != nil {
+				return
+			}
+
+			if err = index.Put(accountMatchKey(player.AccountID, m.ID), nil); err != nil {
+				return
+			}
+		}
+
+		if err = tx.Bucket(BUCKET_MATCHES_BY_END).Put(endKey(m.Ended, m.ID), nil); err != nil {
+			return
+		}
+
+		return put(matches, itob(m.ID), &m)
+	})
+
+	return m, err
+}
+
+func (s *Store) Match(id uint64) (m api.MatchRecord, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		return get(tx.Bucket(BUCKET_MATCHES), itob(id), &m)
+	})
+
+	return
+}
+
+// The account's most recent matches, newest first
+func (s *Store) Matches(account uint64, limit int) (history []api.MatchRecord, err error) {
+	if limit <= 0 {
+		limit = DEFAULT_MATCH_HISTORY
+	}
+
+	limit = min(limit, MAX_MATCH_HISTORY)
+	history = make([]api.MatchRecord, 0)
+
+	err = s.db.View(func(tx *bolt.Tx) (err error) {
+		if err = exists(tx, account); err != nil {
+			return
+		}
+
+		var (
+			prefix  []byte       = itob(account)
+			cursor  *bolt.Cursor = tx.Bucket(BUCKET_ACCOUNT_MATCHES).Cursor()
+			matches *bolt.Bucket = tx.Bucket(BUCKET_MATCHES)
+			key     []byte
+		)
+
+		// Start past the account's last key and walk back
+		if key, _ = cursor.Seek(itob(account + 1)); key == nil {
+			key, _ = cursor.Last()
+		} else {
+			key, _ = cursor.Prev()
+		}
+
+		for ; key != nil && bytes.HasPrefix(key, prefix) && len(history) < limit; key, _ = cursor.Prev() {
+			var m api.MatchRecord
+			if err = get(matches, key[len(prefix):], &m); err != nil {
+				return
+			}
+
+			history = append(history, m)
+		}
+
+		return
+	})
+
+	return
+}
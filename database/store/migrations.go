@@ -0,0 +1,98 @@
+package store
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/z46-dev/game-dev-project/database/api"
+	bolt "go.etcd.io/bbolt"
+)
+
+type Migration struct {
+	Description string
+	Apply       func(tx *bolt.Tx) error
+}
+
+// Applied in order, each in its own transaction together with the version bump. The schema version is the
+// number applied, so entries are only ever appended.
+var migrations []Migration = []Migration{
+	{"Create the account, stats, unlock and match buckets", func(tx *bolt.Tx) (err error) {
+		for _, name := range [][]byte{BUCKET_ACCOUNTS, BUCKET_ACCOUNT_NAMES, BUCKET_STATS, BUCKET_UNLOCKS, BUCKET_MATCHES, BUCKET_ACCOUNT_MATCHES} {
+			if _, err = tx.CreateBucketIfNotExists(name); err != nil {
+				return
+			}
+		}
+
+		return
+	}},
+	{"Add password credentials and login sessions", func(tx *bolt.Tx) (err error) {
+		if _, err = tx.CreateBucketIfNotExists(BUCKET_CREDENTIALS); err == nil {
+			_, err = tx.CreateBucketIfNotExists(BUCKET_SESSIONS)
+		}
+
+		return
+	}},
+	{"Add experience and credit wallets", func(tx *bolt.Tx) (err error) {
+		_, err = tx.CreateBucketIfNotExists(BUCKET_WALLETS)
+		return
+	}},
+	{"Index matches by end time for leaderboard windows", func(tx *bolt.Tx) (err error) {
+		var index *bolt.Bucket
+		if index, err = tx.CreateBucketIfNotExists(BUCKET_MATCHES_BY_END); err != nil {
+			return
+		}
+
+		return tx.Bucket(BUCKET_MATCHES).ForEach(func(key, value []byte) (err error) {
+			var m api.MatchRecord
+			if err = json.Unmarshal(value, &m); err == nil {
+				err = index.Put(endKey(m.Ended, m.ID), nil)
+			}
+
+			return
+		})
+	}},
+}
+
+// Version the code expects
+func LatestSchema() int {
+	return len(migrations)
+}
+
+func (s *Store) migrate() (err error) {
+	if err = s.db.Update(func(tx *bolt.Tx) (err error) {
+		var meta *bolt.Bucket
+		if meta, err = tx.CreateBucketIfNotExists(BUCKET_META); err != nil {
+			return
+		}
+
+		if data := meta.Get(KEY_SCHEMA); data != nil {
+			s.schema = int(binary.BigEndian.Uint32(data))
+		}
+
+		return
+	}); err != nil {
+		return
+	}
+
+	if s.schema > len(migrations) {
+		return fmt.Errorf("schema v%d was written by a newer version, this one knows up to v%d", s.schema, len(migrations))
+	}
+
+	for s.schema < len(migrations) {
+		var m Migration = migrations[s.schema]
+		if err = s.db.Update(func(tx *bolt.Tx) (err error) {
+			if err = m.Apply(tx); err != nil {
+				return
+			}
+
+			return tx.Bucket(BUCKET_META).Put(KEY_SCHEMA, binary.BigEndian.AppendUint32(nil, uint32(s.schema+1)))
+		}); err != nil {
+			return fmt.Errorf("migration to v%d (%s): %w", s.schema+1, m.Description, err)
+		}
+
+		s.schema++
+	}
+
+	return
+}
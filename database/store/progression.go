@@ -0,0 +1,92 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/z46-dev/game-dev-project/database/api"
+	"github.com/z46-dev/game-dev-project/shared/definitions"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Empty for an account that has not earned anything yet
+func wallet(tx *bolt.Tx, id uint64) (w api.Wallet, err error) {
+	if err = get(tx.Bucket(BUCKET_WALLETS), itob(id), &w); errors.Is(err, api.ErrNotFound) {
+		err = nil
+	}
+
+	return
+}
+
+func progress(tx *bolt.Tx, id uint64) (p api.Progress, err error) {
+	if p.Ships, err = unlocks(tx, id); err == nil {
+		p.Wallet, err = wallet(tx, id)
+	}
+
+	return
+}
+
+func (s *Store) Progress(id uint64) (p api.Progress, err error) {
+	err = s.db.View(func(tx *bolt.Tx) (err error) {
+		p, err = progress(tx, id)
+		return
+	})
+
+	return
+}
+
+// Adds earnings to the account's wallet
+func (s *Store) Award(id uint64, earned api.Wallet) (p api.Progress, err error) {
+	if earned.XP < 0 || earned.Credits < 0 {
+		return p, fmt.Errorf("%w: earnings cannot be negative", api.ErrInvalid)
+	}
+
+	err = s.db.Update(func(tx *bolt.Tx) (err error) {
+		if p, err = progress(tx, id); err != nil {
+			return
+		}
+
+		p.XP += earned.XP
+		p.Credits += earned.Credits
+		return put(tx.Bucket(BUCKET_WALLETS), itob(id), &p.Wallet)
+	})
+
+	return
+}
+
+// Unlocks a ship once the tech tree allows it, paying its research cost in the same transaction. Unlocking one
+// the account already has changes nothing.
+func (s *Store) Unlock(id uint64, ship definitions.ShipID) (p api.Progress, err error) {
+	def, found := definitions.GetByKey(definitions.ShipConfigs, ship)
+	if !found {
+		return p, fmt.Errorf("%w: unknown ship %d", api.ErrInvalid, ship)
+	}
+
+	err = s.db.Update(func(tx *bolt.Tx) (err error) {
+		if p, err = progress(tx, id); err != nil {
+			return
+		}
+
+		if definitions.CanSelect(def, p.Ships) {
+			return
+		}
+
+		if err = definitions.CanResearch(def, p.Ships, p.XP, p.Credits); err != nil {
+			return fmt.Errorf("%w: %v", api.ErrInvalid, err)
+		}
+
+		p.XP -= def.Research.XP
+		p.Credits -= def.Research.Credits
+		index, _ := slices.BinarySearch(p.Ships, ship)
+		p.Ships = slices.Insert(p.Ships, index, ship)
+
+		if err = put(tx.Bucket(BUCKET_WALLETS), itob(id), &p.Wallet); err != nil {
+			return
+		}
+
+		return put(tx.Bucket(BUCKET_UNLOCKS), itob(id), p.Ships)
+	})
+
+	return
+}
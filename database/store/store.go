@@ -0,0 +1,88 @@
+// Package store keeps accounts, stats, wallets, unlocked ships and match records in a single bbolt file. Values are
+// JSON, keys are big endian IDs so cursors walk them in order.
+package store
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/z46-dev/game-dev-project/database/api"
+	bolt "go.etcd.io/bbolt"
+)
+
+const OPEN_TIMEOUT time.Duration = time.Second // How long to wait for another process to let go of the file
+
+var (
+	BUCKET_META            []byte = []byte("meta")
+	BUCKET_ACCOUNTS        []byte = []byte("accounts")        // ID -> api.Account
+	BUCKET_ACCOUNT_NAMES   []byte = []byte("account_names")   // Lowercase name -> ID
+	BUCKET_STATS           []byte = []byte("stats")           // Account ID -> api.Stats
+	BUCKET_UNLOCKS         []byte = []byte("unlocks")         // Account ID -> []definitions.ShipID
+	BUCKET_MATCHES         []byte = []byte("matches")         // ID -> api.MatchRecord
+	BUCKET_ACCOUNT_MATCHES []byte = []byte("account_matches") // Account ID then match ID -> nothing
+	BUCKET_CREDENTIALS     []byte = []byte("credentials")     // Account ID -> credential
+	BUCKET_SESSIONS        []byte = []byte("sessions")        // SHA-256 of the token -> session
+	BUCKET_WALLETS         []byte = []byte("wallets")         // Account ID -> api.Wallet
+	BUCKET_MATCHES_BY_END  []byte = []byte("matches_by_end")  // End time in Unix nanoseconds then match ID -> nothing
+
+	KEY_SCHEMA []byte = []byte("schema")
+)
+
+type Store struct {
+	db              *bolt.DB
+	schema          int
+	SessionLifetime time.Duration // How long a login stays valid
+}
+
+// Opens or creates the file and brings its schema up to date
+func Open(path string) (s *Store, err error) {
+	var db *bolt.DB
+	if db, err = bolt.Open(path, 0o600, &bolt.Options{Timeout: OPEN_TIMEOUT}); err != nil {
+		return
+	}
+
+	s = &Store{db: db, SessionLifetime: DEFAULT_SESSION_LIFETIME}
+	if err = s.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Schema version the file is at
+func (s *Store) Schema() int {
+	return s.schema
+}
+
+func itob(id uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, id)
+}
+
+func get(bucket *bolt.Bucket, key []byte, out any) (err error) {
+	var data []byte = bucket.Get(key)
+	if data == nil {
+		return api.ErrNotFound
+	}
+
+	if err = json.Unmarshal(data, out); err != nil {
+		err = fmt.Errorf("decode %x: %w", key, err)
+	}
+
+	return
+}
+
+func put(bucket *bolt.Bucket, key []byte, value any) (err error) {
+	var data []byte
+	if data, err = json.Marshal(value); err != nil {
+		return
+	}
+
+	return bucket.Put(key, data)
+}
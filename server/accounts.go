@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/z46-dev/game-dev-project/database/api"
+	"github.com/z46-dev/game-dev-project/server/config"
+	"github.com/z46-dev/game-dev-project/server/game"
+	"github.com/z46-dev/game-dev-project/shared/names"
+	"github.com/z46-dev/game-dev-project/shared/protocol"
+)
+
+const (
+	LOGIN_ATTEMPTS_PER_MINUTE float64 = 10 // Per IP, across every account endpoint
+	LOGIN_ATTEMPT_BURST       float64 = 5
+	MAX_LOGIN_BODY            int64   = 4096
+)
+
+type (
+	// Body of POST /register and POST /login
+	LoginRequest struct {
+		Name, Password string
+	}
+
+	// What a client needs to join with its account
+	LoginResponse struct {
+		Token   string
+		Name    string
+		Expires time.Time
+	}
+
+	loginBucket struct {
+		tokens float64
+		last   time.Time
+	}
+)
+
+var (
+	loginBuckets   map[string]*loginBucket = make(map[string]*loginBucket)
+	loginBucketsMu sync.Mutex
+)
+
+// Token bucket per IP, so passwords cannot be guessed at the rate the hashing allows
+func allowLoginAttempt(ip string) bool {
+	loginBucketsMu.Lock()
+	defer loginBucketsMu.Unlock()
+
+	var now time.Time = time.Now()
+	var bucket *loginBucket = loginBuckets[ip]
+	if bucket == nil {
+		// Drop buckets that have refilled while making room for this one, nothing is lost by forgetting them
+		for key, old := range loginBuckets {
+			if now.Sub(old.last).Minutes()*LOGIN_ATTEMPTS_PER_MINUTE >= LOGIN_ATTEMPT_BURST {
+				delete(loginBuckets, key)
+			}
+		}
+
+		bucket = &loginBucket{tokens: LOGIN_ATTEMPT_BURST, last: now}
+		loginBuckets[ip] = bucket
+	}
+
+	bucket.tokens = min(LOGIN_ATTEMPT_BURST, bucket.tokens+now.Sub(bucket.last).Minutes()*LOGIN_ATTEMPTS_PER_MINUTE)
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		return false
+	}
+
+	bucket.tokens--
+	return true
+}
+
+func writeAccountError(writer http.ResponseWriter, status int, message string) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	json.NewEncoder(writer).Encode(&api.ErrorResponse{Error: message})
+}
+
+// Shared by the account endpoints: CORS, rate limiting and decoding. Returns false once it has answered.
+func readAccountRequest(writer http.ResponseWriter, request *http.Request, body any) bool {
+	writer.Header().Set("Access-Control-Allow-Origin", "*")
+	writer.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	if db == nil {
+		writeAccountError(writer, http.StatusNotFound, "This server does not have accounts")
+		return false
+	}
+
+	if !allowLoginAttempt(remoteIP(request)) {
+		writeAccountError(writer, http.StatusTooManyRequests, "Too many attempts, wait a minute and try again")
+		return false
+	}
+
+	if err := json.NewDecoder(http.MaxBytesReader(writer, request.Body, MAX_LOGIN_BODY)).Decode(body); err != nil {
+		writeAccountError(writer, http.StatusBadRequest, "Malformed request")
+		return false
+	}
+
+	return true
+}
+
+// Logs in with a name and password and hands back a session token
+func login(writer http.ResponseWriter, body *LoginRequest) {
+	session, err := db.Login(body.Name, body.Password)
+	switch {
+	case errors.Is(err, api.ErrUnauthorized):
+		writeAccountError(writer, http.StatusUnauthorized, "Wrong name or password")
+		return
+	case err != nil:
+		log.Errorf("Login failed: %v", err)
+		writeAccountError(writer, http.StatusServiceUnavailable, "Accounts are unavailable right now")
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(writer).Encode(&LoginResponse{Token: session.Token, Name: session.Account.Name, Expires: session.Expires})
+}
+
+func handleRegister(writer http.ResponseWriter, request *http.Request) {
+	var body LoginRequest
+	if !readAccountRequest(writer, request, &body) {
+		return
+	}
+
+	account, err := db.Register(body.Name, body.Password)
+	switch {
+	case errors.Is(err, api.ErrConflict):
+		writeAccountError(writer, http.StatusConflict, "That name is taken")
+		return
+	case errors.Is(err, api.ErrInvalid):
+		writeAccountError(writer, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		log.Errorf("Registration failed: %v", err)
+		writeAccountError(writer, http.StatusServiceUnavailable, "Accounts are unavailable right now")
+		return
+	}
+
+	log.Infof("Registered account #%d %q", account.ID, account.Name)
+	login(writer, &body)
+}
+
+func handleLogin(writer http.ResponseWriter, request *http.Request) {
+	var body LoginRequest
+	if readAccountRequest(writer, request, &body) {
+		login(writer, &body)
+	}
+}
+
+// Works out who is joining. Players with a token play as their account, guests under the name they asked
+// for if the room allows them. A non-empty reason means the join is rejected.
+func identify(join *protocol.Join) (name string, accountID uint64, reason string) {
+	if join.Token != "" {
+		if db == nil {
+			return "", 0, "This server does not have accounts, join as a guest"
+		}
+
+		account, err := db.ValidateSession(join.Token)
+		switch {
+		case errors.Is(err, api.ErrUnauthorized):
+			return "", 0, "Your session has expired, please log in again"
+		case err != nil:
+			log.Errorf("Session check failed: %v", err)
+			return "", 0, "Accounts are unavailable right now, try again later"
+		}
+
+		return account.Name, account.ID, ""
+	}
+
+	if !config.Config.Accounts.AllowGuests {
+		return "", 0, "Log in to play on this server"
+	}
+
+	if err := names.Validate(join.Name); err != nil {
+		return "", 0, fmt.Sprintf("Invalid name: %v", err)
+	}
+
+	// Guests cannot pass for registered players. If the service is down they are let in rather than locked out.
+	if db != nil {
+		if _, err := db.AccountByName(join.Name); err == nil {
+			return "", 0, "That name belongs to an account, log in to use it"
+		} else if !errors.Is(err, api.ErrNotFound) {
+			log.Warningf("Could not check guest name %q: %v", join.Name, err)
+		}
+	}
+
+	return join.Name, 0, ""
+}
+
+// Disconnects the players a newer login on their account took over from, they are already out of the game
+func kickDisplaced(displaced []*game.Player) {
+	for _, p := range displaced {
+		p.Socket.Logger.Info("Kicked for logging in again elsewhere")
+		disconnect(p.Socket, p.Protocol, protocol.PACKET_CLIENTBOUND_KICK, "Logged in from another connection")
+	}
+}
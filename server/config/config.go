@@ -15,6 +15,32 @@ type Configuration struct {
 		Address string `toml:"address" default:":3000" validate:"required"` // Listen address for the web application server e.g. ":8080" or "0.0.0.0:8080"
 		TLSDir  string `toml:"tls_dir" default:""`                          // Directory containing a crt and a key file for TLS. Leave empty to use HTTP instead of HTTPS.
 	} `toml:"web_server"` // Web server configuration
+	Game struct {
+		Room              string  `toml:"room" default:"default"`                           // Name clients must ask for when joining
+		SpectatorFogOfWar bool    `toml:"spectator_fog_of_war" default:"true"`              // Restrict spectators to what their faction can see
+		SpectatorMaxFOV   float64 `toml:"spectator_max_fov" default:"6000"`                 // Largest field of view a spectator may zoom out to
+		MapExtent         float64 `toml:"map_extent" default:"8192"`                        // Half the width of the playable area, used for minimap quantization
+		MatchLength       int     `toml:"match_length" default:"0"`                         // Match length in seconds, 0 for an open-ended world
+		Seed              uint64  `toml:"seed" default:"0"`                                 // Simulation RNG seed, 0 picks a random one (it is logged either way)
+		InputLog          string  `toml:"input_log" default:""`                             // File to log the seed, every player input and periodic state hashes to, for tools/replay. Empty to disable.
+		Snapshot          string  `toml:"snapshot" default:""`                              // World snapshot restored at start if it exists and saved to while running. Empty to always build a new world.
+		AutosaveInterval  int     `toml:"autosave_interval" default:"300" validate:"gte=0"` // Seconds between snapshot saves, 0 to only save it on shutdown
+	} `toml:"game"` // Room rules applied to the running game
+	Network struct {
+		PositionPrecision float64 `toml:"position_precision" default:"0.125" validate:"gt=0"`    // World units per step of a quantized, camera relative position
+		MaxRewind         int     `toml:"max_rewind_ms" default:"200" validate:"gte=0,lte=1000"` // Furthest back in time aimed shots are checked against what a lagging player saw
+		ViewBudget        int     `toml:"view_budget" default:"2048" validate:"gte=256"`         // Bytes per view update before less important ships are held back to a later tick
+	} `toml:"network"` // Wire format tuning
+	Database struct {
+		URL    string `toml:"url" default:""`     // Base URL of the database service e.g. "http://127.0.0.1:3100". Empty to run without one.
+		APIKey string `toml:"api_key" default:""` // Must match api.key in the service's database_server.toml
+	} `toml:"database"` // Database service the game server stores accounts and stats through
+	Accounts struct {
+		AllowGuests bool `toml:"allow_guests" default:"true"` // Let players without an account join under any free, valid name
+	} `toml:"accounts"` // Who may join
+	Chat struct {
+		BannedWords []string `toml:"banned_words"` // Words replaced with asterisks in player messages
+	} `toml:"chat"` // Chat moderation
 }
 
 var (
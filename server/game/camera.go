@@ -1,6 +1,8 @@
 package game
 
 import (
+	"math"
+
 	"github.com/z46-dev/game-dev-project/shared/protocol"
 	"github.com/z46-dev/game-dev-project/util"
 )
@@ -8,8 +10,12 @@ import (
 func NewCamera(fov float32) (c *Camera) {
 	c = &Camera{
 		Position:        util.Vector(0, 0),
+		Center:          util.Vector(0, 0),
 		FOV:             float64(fov),
 		ShipsSeen:       make(map[uint64]bool),
+		ShipsSent:       make(map[uint64]*SentShip),
+		Priority:        make(map[uint64]float64),
+		IslandsSeen:     make(map[uint64]bool),
 		ProjectilesSeen: make(map[uint64]bool),
 	}
 
@@ -26,7 +32,27 @@ func (c *Camera) IsInView(aabb *util.AABB) (inside bool) {
 	return
 }
 
+func shipHull(o *Ship) (hull []protocol.Point) {
+	hull = make([]protocol.Point, len(o.Polygon.Reference))
+	for i, p := range o.Polygon.Reference {
+		hull[i] = protocol.Point{X: float32(p.X), Y: float32(p.Y)}
+	}
+
+	return
+}
+
 func (c *Camera) SeeShip(w *protocol.Writer, o *Ship) {
+	// Quantized positions depend on the camera, so nothing can be shared between players
+	if w.Version >= protocol.PROTOCOL_QUANTIZED_VERSION {
+		c.seeShipQuantized(w, o)
+		return
+	}
+
+	w.SetVarU64(o.ID)
+	w.SetU8(protocol.ENTITY_TYPE_SHIP)
+
+	var version uint16 = max(1, w.Version)
+
 	var cache *ShipCache
 	o.Game.ShipCacheMu.RLock()
 	cache = o.Game.ShipCache[o.ID]
@@ -64,8 +90,8 @@ func (c *Camera) SeeShip(w *protocol.Writer, o *Ship) {
 		cache.AsOf = o.Game.time
 
 		// Clear the buffers
-		cache.New = nil
-		cache.Old = nil
+		clear(cache.New[:])
+		clear(cache.Old[:])
 	}
 
 	// Are we new?
@@ -73,92 +99,204 @@ func (c *Camera) SeeShip(w *protocol.Writer, o *Ship) {
 		c.ShipsSeen[o.ID] = true
 
 		// Build new buffer if needed
-		if cache.New == nil {
-			cache.New = new(protocol.Writer)
-
-			cache.New.SetU8(0)
-			cache.New.SetF32(float32(o.Position.X))
-			cache.New.SetF32(float32(o.Position.Y))
-			cache.New.SetF32(float32(o.Size))
-			cache.New.SetF32(float32(o.Rotation))
-			cache.New.SetStringUTF8(o.Name)
-
-			cache.New.SetU16(uint16(len(o.Polygon.Reference)))
-			for _, p := range o.Polygon.Reference {
-				cache.New.SetF32(float32(p.X))
-				cache.New.SetF32(float32(p.Y))
+		if cache.New[version] == nil {
+			var create *protocol.ShipCreate = &protocol.ShipCreate{
+				X:        float32(o.Position.X),
+				Y:        float32(o.Position.Y),
+				Size:     float32(o.Size),
+				Rotation: float32(o.Rotation),
+				Name:     o.Name,
+				Hull:     shipHull(o),
+				ShipID:   uint8(o.Cfg.ID),
+				Health:   float32(o.Health.Ratio()),
 			}
 
-			cache.New.SetU8(uint8(o.Cfg.ID))
-			cache.New.SetF32(float32(o.Health.Ratio()))
+			cache.New[version] = protocol.NewWriter(version)
+			cache.New[version].SetU8(0)
+			create.Write(cache.New[version])
 		}
 
 		// Send new buffer
-		w.Append(cache.New)
+		w.Append(cache.New[version])
 	} else {
 		// Build old buffer if needed
-		if cache.Old == nil {
-			cache.Old = new(protocol.Writer)
-			cache.Old.SetU8(1)
+		if cache.Old[version] == nil {
+			var update *protocol.ShipUpdate = &protocol.ShipUpdate{
+				X:        float32(o.Position.X),
+				Y:        float32(o.Position.Y),
+				Size:     float32(o.Size),
+				Rotation: float32(o.Rotation),
+				Health:   float32(o.Health.Ratio()),
+			}
 
-			var flags uint8 = 0
 			if cache.PosChanged {
-				flags |= 1 << 0
+				update.Flags |= protocol.BITFLAG_SHIP_UPDATE_POSITION
 			}
 
 			if cache.SizeChanged {
-				flags |= 1 << 1
+				update.Flags |= protocol.BITFLAG_SHIP_UPDATE_SIZE
 			}
 
 			if cache.RotChanged {
-				flags |= 1 << 2
+				update.Flags |= protocol.BITFLAG_SHIP_UPDATE_ROTATION
 			}
 
 			if cache.HealthChanged {
-				flags |= 1 << 3
+				update.Flags |= protocol.BITFLAG_SHIP_UPDATE_HEALTH
 			}
 
-			cache.Old.SetU8(flags)
-
-			if cache.PosChanged {
-				cache.Old.SetF32(float32(o.Position.X))
-				cache.Old.SetF32(float32(o.Position.Y))
-			}
+			cache.Old[version] = protocol.NewWriter(version)
+			cache.Old[version].SetU8(1)
+			update.Write(cache.Old[version])
+		}
 
-			if cache.SizeChanged {
-				cache.Old.SetF32(float32(o.Size))
-			}
+		// Send old buffer
+		w.Append(cache.Old[version])
+	}
+}
 
-			if cache.RotChanged {
-				cache.Old.SetF32(float32(o.Rotation))
-			}
+// Positions are sent as offsets from the header's camera center, and ships whose state changed by less
+// than a quantization step are left out of the update entirely
+func (c *Camera) seeShipQuantized(w *protocol.Writer, o *Ship) {
+	var (
+		precision float64 = o.Game.Settings.PositionPrecision
+		offsetX   uint16  = protocol.QuantizeOffset(o.Position.X, c.Center.X, precision)
+		offsetY   uint16  = protocol.QuantizeOffset(o.Position.Y, c.Center.Y, precision)
+		angle     uint16  = protocol.QuantizeAngle12(o.Rotation)
+		health    float64 = o.Health.Ratio()
+	)
 
-			if cache.HealthChanged {
-				cache.Old.SetF32(float32(o.Health.Ratio()))
-			}
+	sent, seen := c.ShipsSent[o.ID]
+	if !seen {
+		c.ShipsSeen[o.ID] = true
+		c.ShipsSent[o.ID] = &SentShip{
+			X:      protocol.DequantizeOffset(offsetX, c.Center.X, precision),
+			Y:      protocol.DequantizeOffset(offsetY, c.Center.Y, precision),
+			Angle:  angle,
+			Size:   o.Size,
+			Health: health,
 		}
 
-		// Send old buffer
-		w.Append(cache.Old)
+		w.SetVarU64(o.ID)
+		w.SetU8(protocol.ENTITY_TYPE_SHIP)
+		w.SetU8(0)
+		(&protocol.ShipCreate{
+			OffsetX: offsetX,
+			OffsetY: offsetY,
+			Size:    float32(o.Size),
+			Angle:   angle,
+			Name:    o.Name,
+			Hull:    shipHull(o),
+			ShipID:  uint8(o.Cfg.ID),
+			Health:  float32(health),
+		}).Write(w)
+
+		return
+	}
+
+	var update *protocol.ShipUpdate = &protocol.ShipUpdate{
+		OffsetX: offsetX,
+		OffsetY: offsetY,
+		Size:    float32(o.Size),
+		Angle:   angle,
+		Health:  float32(health),
+	}
+
+	if math.Abs(o.Position.X-sent.X) >= precision || math.Abs(o.Position.Y-sent.Y) >= precision {
+		update.Flags |= protocol.BITFLAG_SHIP_UPDATE_POSITION
+		sent.X = protocol.DequantizeOffset(offsetX, c.Center.X, precision)
+		sent.Y = protocol.DequantizeOffset(offsetY, c.Center.Y, precision)
+	}
+
+	if o.Size != sent.Size {
+		update.Flags |= protocol.BITFLAG_SHIP_UPDATE_SIZE
+		sent.Size = o.Size
+	}
+
+	if angle != sent.Angle {
+		update.Flags |= protocol.BITFLAG_SHIP_UPDATE_ROTATION
+		sent.Angle = angle
+	}
+
+	if health != sent.Health {
+		update.Flags |= protocol.BITFLAG_SHIP_UPDATE_HEALTH
+		sent.Health = health
 	}
+
+	if update.Flags == 0 {
+		return
+	}
+
+	w.SetVarU64(o.ID)
+	w.SetU8(protocol.ENTITY_TYPE_SHIP)
+	w.SetU8(1)
+	update.Write(w)
+}
+
+// Islands are static, so they are only written once when they first come into view
+func (c *Camera) SeeIsland(w *protocol.Writer, o *Island) {
+	c.IslandsSeen[o.ID] = true
+
+	w.SetVarU64(o.ID)
+	w.SetU8(protocol.ENTITY_TYPE_ISLAND)
+	w.SetU8(0)
+
+	var create *protocol.IslandCreate = &protocol.IslandCreate{
+		X:        float32(o.Position.X),
+		Y:        float32(o.Position.Y),
+		Size:     float32(o.Size),
+		Rotation: float32(o.Rotation),
+		Hull:     make([]protocol.Point, len(o.Polygon.Reference)),
+	}
+
+	for i, p := range o.Polygon.Reference {
+		create.Hull[i] = protocol.Point{X: float32(p.X), Y: float32(p.Y)}
+	}
+
+	create.Write(w)
 }
 
 func (c *Camera) See(g *Game, player *Player, w *protocol.Writer) {
-	w.SetU32(uint32(g.time))
-	w.SetF32(float32(c.Position.X))
-	w.SetF32(float32(c.Position.Y))
-	w.SetF32(float32(c.FOV))
+	if player.Spectator != nil {
+		player.Spectator.Update(g, c)
+	}
 
-	if player.Body == nil {
-		w.SetU64(0)
-	} else {
+	if player.Body != nil {
 		c.Position = player.Body.Position
-		w.SetU64(player.Body.ID)
 	}
 
+	var header *protocol.ViewHeader = &protocol.ViewHeader{
+		Time: uint32(g.time),
+		X:    float32(c.Position.X),
+		Y:    float32(c.Position.Y),
+		FOV:  float32(c.FOV),
+	}
+
+	// The body's state lets a predicting client correct itself
+	if player.Body != nil {
+		header.EntityID = player.Body.ID
+		header.InputAck = player.InputAck
+		header.VelocityX = float32(player.Body.Velocity.X)
+		header.VelocityY = float32(player.Body.Velocity.Y)
+		header.Rotation = float32(player.Body.Rotation)
+	}
+
+	// Spectator state, so the client knows what it is looking at
+	if player.Spectator != nil {
+		header.Spectating = 1
+		header.SpectateTarget = player.Spectator.TargetID
+		header.SpectateFlags = player.Spectator.Flags()
+	}
+
+	header.Write(w)
+	c.Center = util.Vector(float64(header.X), float64(header.Y))
+
 	// Entities in View
 	var (
-		shipsSeenNow       = make(map[uint64]bool)
+		shipsSeenNow   = make(map[uint64]bool)
+		islandsSeenNow = make(map[uint64]bool)
+		ships          []*Ship
+		deferred       []uint64
 	)
 
 	for _, something := range g.spatialHash.Retrieve(&util.AABB{
@@ -169,25 +307,66 @@ func (c *Camera) See(g *Game, player *Player, w *protocol.Writer) {
 	}) {
 		switch o := something.(type) {
 		case *Ship:
+			// Fog of war, hidden ships are never written to the client
+			if !player.CanSee(g, o) {
+				continue
+			}
+
 			shipsSeenNow[o.ID] = true
-			w.SetU64(o.ID)
-			w.SetU8(protocol.ENTITY_TYPE_SHIP)
+			ships = append(ships, o)
+		case *Island:
+			islandsSeenNow[o.ID] = true
+			if !c.IslandsSeen[o.ID] {
+				c.SeeIsland(w, o)
+			}
+		}
+	}
+
+	// Islands went first, ships share what is left of the budget
+	if w.Version >= protocol.PROTOCOL_INTEREST_VERSION {
+		deferred = c.seeShipsBudgeted(player, w, ships, g.Settings.ViewByteBudget)
+	} else {
+		for _, o := range ships {
 			c.SeeShip(w, o)
 		}
 	}
 
 	// Say we're done
-	w.SetU64(0)
+	w.SetVarU64(0)
 
 	// Deletes
 	for id := range c.ShipsSeen {
 		if _, stillSeen := shipsSeenNow[id]; !stillSeen {
-			w.SetU64(id)
+			w.SetVarU64(id)
 			w.SetU8(protocol.ENTITY_TYPE_SHIP)
 			delete(c.ShipsSeen, id)
+			delete(c.ShipsSent, id)
+		}
+	}
+
+	for id := range c.Priority {
+		if !shipsSeenNow[id] {
+			delete(c.Priority, id)
+		}
+	}
+
+	for id := range c.IslandsSeen {
+		if _, stillSeen := islandsSeenNow[id]; !stillSeen {
+			w.SetVarU64(id)
+			w.SetU8(protocol.ENTITY_TYPE_ISLAND)
+			delete(c.IslandsSeen, id)
 		}
 	}
 
 	// Say we're done with deletes
-	w.SetU64(0)
+	w.SetVarU64(0)
+
+	// Ships the client knows about that were held back this tick
+	if w.Version >= protocol.PROTOCOL_INTEREST_VERSION {
+		for _, id := range deferred {
+			w.SetVarU64(id)
+		}
+
+		w.SetVarU64(0)
+	}
 }
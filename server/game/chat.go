@@ -0,0 +1,215 @@
+package game
+
+import (
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/z46-dev/game-dev-project/shared/protocol"
+)
+
+const (
+	CHAT_RATE_BURST  float64 = 5         // Messages a player can send back to back
+	CHAT_RATE_REFILL float64 = 1.0 / 1.5 // Messages regained per second
+	CHAT_SYSTEM_NAME string  = "[SERVER]"
+)
+
+type (
+	// Runs on every player message before it is delivered. Returning false drops the message.
+	ChatFilter func(sender *Player, channel uint8, message string) (filtered string, allowed bool)
+
+	ChatLimiter struct {
+		tokens float64
+		last   time.Time
+	}
+
+	MuteList struct {
+		mu    sync.RWMutex
+		until map[string]time.Time
+	}
+)
+
+func NewChatLimiter() (l *ChatLimiter) {
+	l = &ChatLimiter{
+		tokens: CHAT_RATE_BURST,
+		last:   time.Now(),
+	}
+
+	return
+}
+
+// Token bucket, refilled based on the time since the last message
+func (l *ChatLimiter) Allow() (allowed bool) {
+	var now time.Time = time.Now()
+	l.tokens = min(CHAT_RATE_BURST, l.tokens+now.Sub(l.last).Seconds()*CHAT_RATE_REFILL)
+	l.last = now
+
+	if l.tokens < 1 {
+		return false
+	}
+
+	l.tokens--
+	return true
+}
+
+// Replaces every listed word (case insensitive) with asterisks
+func NewWordListFilter(words []string) ChatFilter {
+	var lowered []string = make([]string, 0, len(words))
+	for _, word := range words {
+		if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+			lowered = append(lowered, word)
+		}
+	}
+
+	return func(sender *Player, channel uint8, message string) (string, bool) {
+		var lower string = strings.ToLower(message)
+		if len(lower) != len(message) {
+			// Lowercasing changed the byte layout, fall back to an exact match so indices line up
+			lower = message
+		}
+
+		for _, word := range lowered {
+			for {
+				var index int = strings.Index(lower, word)
+				if index < 0 {
+					break
+				}
+
+				var stars string = strings.Repeat("*", len(word))
+				message = message[:index] + stars + message[index+len(word):]
+				lower = lower[:index] + stars + lower[index+len(word):]
+			}
+		}
+
+		return message, true
+	}
+}
+
+func NewMuteList() (m *MuteList) {
+	m = &MuteList{
+		until: make(map[string]time.Time),
+	}
+
+	return
+}
+
+func (m *MuteList) Mute(name string, duration time.Duration) {
+	m.mu.Lock()
+	m.until[name] = time.Now().Add(duration)
+	m.mu.Unlock()
+}
+
+func (m *MuteList) Unmute(name string) {
+	m.mu.Lock()
+	delete(m.until, name)
+	m.mu.Unlock()
+}
+
+func (m *MuteList) IsMuted(name string) (muted bool) {
+	m.mu.RLock()
+	until, found := m.until[name]
+	m.mu.RUnlock()
+
+	muted = found && time.Now().Before(until)
+	return
+}
+
+func (m *MuteList) Filter() ChatFilter {
+	return func(sender *Player, channel uint8, message string) (string, bool) {
+		if m.IsMuted(sender.Name) {
+			sender.SendChat(protocol.CHAT_CHANNEL_SYSTEM, CHAT_SYSTEM_NAME, "You are muted")
+			return message, false
+		}
+
+		return message, true
+	}
+}
+
+func (g *Game) AddChatFilter(filter ChatFilter) {
+	g.chatMu.Lock()
+	g.chatFilters = append(g.chatFilters, filter)
+	g.chatMu.Unlock()
+}
+
+func (p *Player) SendChat(channel uint8, sender, message string) {
+	var w *protocol.Writer = p.NewWriter()
+	w.SetU8(protocol.PACKET_CLIENTBOUND_CHAT)
+	(&protocol.ChatReceive{Channel: channel, Sender: sender, Message: message}).Write(w)
+	p.Socket.Write(w.GetBytes())
+}
+
+func (g *Game) broadcastChat(channel uint8, sender, message string, include func(*Player) bool) {
+	g.PlayersMu.RLock()
+	defer g.PlayersMu.RUnlock()
+
+	for _, player := range g.Players {
+		if include == nil || include(player) {
+			player.SendChat(channel, sender, message)
+		}
+	}
+}
+
+// Kills, captures, joins and so on
+func (g *Game) SystemMessage(message string) {
+	g.broadcastChat(protocol.CHAT_CHANNEL_SYSTEM, CHAT_SYSTEM_NAME, message, nil)
+}
+
+func (g *Game) FindPlayerByName(name string) (found *Player) {
+	g.PlayersMu.RLock()
+	defer g.PlayersMu.RUnlock()
+
+	for _, player := range g.Players {
+		if strings.EqualFold(player.Name, name) {
+			return player
+		}
+	}
+
+	return nil
+}
+
+func (g *Game) HandleChat(sender *Player, channel uint8, target, message string) {
+	if message = strings.TrimSpace(message); message == "" {
+		return
+	}
+
+	if utf8.RuneCountInString(message) > protocol.CHAT_MAX_LENGTH {
+		message = string([]rune(message)[:protocol.CHAT_MAX_LENGTH])
+	}
+
+	if !sender.ChatLimiter.Allow() {
+		sender.SendChat(protocol.CHAT_CHANNEL_SYSTEM, CHAT_SYSTEM_NAME, "You are sending messages too quickly")
+		return
+	}
+
+	g.chatMu.RLock()
+	var filters []ChatFilter = g.chatFilters
+	g.chatMu.RUnlock()
+
+	for _, filter := range filters {
+		var allowed bool
+		if message, allowed = filter(sender, channel, message); !allowed {
+			return
+		}
+	}
+
+	switch channel {
+	case protocol.CHAT_CHANNEL_ALL:
+		g.broadcastChat(channel, sender.Name, message, nil)
+	case protocol.CHAT_CHANNEL_FACTION:
+		g.broadcastChat(channel, sender.Name, message, func(p *Player) bool {
+			return sender.Faction.IsAlliedWith(p.Faction)
+		})
+	case protocol.CHAT_CHANNEL_WHISPER:
+		var recipient *Player = g.FindPlayerByName(target)
+		if recipient == nil {
+			sender.SendChat(protocol.CHAT_CHANNEL_SYSTEM, CHAT_SYSTEM_NAME, "No player named "+target)
+			return
+		}
+
+		recipient.SendChat(channel, sender.Name, message)
+		if recipient != sender {
+			sender.SendChat(channel, "-> "+recipient.Name, message)
+		}
+	}
+}
@@ -285,6 +285,15 @@ func shipShipCollision(o *Ship, n *Ship) {
 // 	b.Velocity.Add(normal.Copy().Scale(impulse * invB))
 // }
 
+func shipIslandCollision(o *Ship, n *Island) {
+	if !util.TwoPolygonsIntersect(o.Polygon, n.Polygon) {
+		return
+	}
+
+	// Islands have no push weight, so the ship takes the entire resolution
+	applyMTVResolution(&o.PolygonalCollisionPlugin, &n.PolygonalCollisionPlugin)
+}
+
 func collideObjects(game *Game, self CollidableObject) {
 	if game == nil {
 		return
@@ -306,6 +315,8 @@ func collideObjects(game *Game, self CollidableObject) {
 			switch other := c.(type) {
 			case *Ship:
 				shipShipCollision(my, other)
+			case *Island:
+				shipIslandCollision(my, other)
 			// case *Projectile:
 				// shipProjectileCollision(my, other)
 			}
@@ -0,0 +1,198 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/z46-dev/game-dev-project/shared/protocol"
+)
+
+const (
+	INPUT_RATE_BURST     float64 = float64(TPS) * 2   // Inputs a connection can send back to back
+	INPUT_RATE_REFILL    float64 = float64(TPS) * 2.5 // Inputs regained per second, older clients send one per mouse move at 60 FPS
+	PACKET_RATE_BURST    float64 = 20                 // Any other packet, chat has its own limit on top
+	PACKET_RATE_REFILL   float64 = 10
+	MAX_TARGET_DISTANCE  float64 = 8192 // Past the edge of the widest view, further than this is not a real cursor
+	CONDUCT_WARN_STRIKES float64 = 5    // Strikes before a warning
+	CONDUCT_KICK_STRIKES float64 = 15   // Strikes before a kick
+	CONDUCT_FORGIVENESS  float64 = 0.5  // Strikes forgiven per second
+	CONDUCT_HISTORY_SIZE int     = 32   // Recent violations kept for review
+)
+
+const (
+	VIOLATION_INPUT_RATE  string = "input rate"
+	VIOLATION_PACKET_RATE string = "packet rate"
+	VIOLATION_TARGET      string = "target"
+	VIOLATION_SEQUENCE    string = "sequence"
+	VIOLATION_MALFORMED   string = "malformed"
+)
+
+const (
+	CONDUCT_DROP uint8 = iota // Ignore the packet
+	CONDUCT_WARN              // Ignore it and tell the player
+	CONDUCT_KICK              // Disconnect them
+)
+
+type (
+	// Token bucket, refilled based on the time since the last packet
+	RateLimiter struct {
+		tokens, burst, refill float64
+		last                  time.Time
+	}
+
+	// One broken rule. Doubles as the error handlePacket returns, so it can be told apart from a decode error.
+	Violation struct {
+		At     time.Time
+		Kind   string
+		Detail string
+	}
+
+	// A player's record of broken rules. Every violation drops the packet, enough of them in a short time earn
+	// a warning and more after that a kick. Strikes are slowly forgiven so an occasional hiccup never adds up.
+	Conduct struct {
+		mu              sync.Mutex
+		Inputs, Packets *RateLimiter
+		strikes         float64
+		last            time.Time
+		warned          bool
+		Recent          []Violation // Newest last
+		Counts          map[string]int
+	}
+)
+
+func NewRateLimiter(burst, refill float64) (l *RateLimiter) {
+	l = &RateLimiter{
+		tokens: burst,
+		burst:  burst,
+		refill: refill,
+		last:   time.Now(),
+	}
+
+	return
+}
+
+func (l *RateLimiter) Allow() (allowed bool) {
+	var now time.Time = time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.refill)
+	l.last = now
+
+	if l.tokens < 1 {
+		return false
+	}
+
+	l.tokens--
+	return true
+}
+
+func (v *Violation) Error() string {
+	return v.Kind + ": " + v.Detail
+}
+
+func NewConduct() (c *Conduct) {
+	c = &Conduct{
+		Inputs:  NewRateLimiter(INPUT_RATE_BURST, INPUT_RATE_REFILL),
+		Packets: NewRateLimiter(PACKET_RATE_BURST, PACKET_RATE_REFILL),
+		last:    time.Now(),
+		Counts:  make(map[string]int),
+	}
+
+	return
+}
+
+// Rate limits a packet from a joined player, nil if it may be handled
+func (c *Conduct) Allow(packetType uint8) (violation *Violation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if packetType == protocol.PACKET_SERVERBOUND_INPUT {
+		if !c.Inputs.Allow() {
+			violation = &Violation{Kind: VIOLATION_INPUT_RATE, Detail: "too many inputs"}
+		}
+
+		return
+	}
+
+	if !c.Packets.Allow() {
+		violation = &Violation{Kind: VIOLATION_PACKET_RATE, Detail: fmt.Sprintf("too many packets of type %d", packetType)}
+	}
+
+	return
+}
+
+// Records a violation and decides what to do about it
+func (c *Conduct) Record(v *Violation) (action uint8) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if v.At.IsZero() {
+		v.At = time.Now()
+	}
+
+	if len(c.Recent) == CONDUCT_HISTORY_SIZE {
+		c.Recent = append(c.Recent[:0], c.Recent[1:]...)
+	}
+
+	c.Recent = append(c.Recent, *v)
+	c.Counts[v.Kind]++
+
+	// Once everything is forgiven the next run of violations gets a fresh warning
+	if c.strikes = max(0, c.strikes-v.At.Sub(c.last).Seconds()*CONDUCT_FORGIVENESS); c.strikes == 0 {
+		c.warned = false
+	}
+
+	c.strikes++
+	c.last = v.At
+
+	switch {
+	case c.strikes >= CONDUCT_KICK_STRIKES:
+		return CONDUCT_KICK
+	case c.strikes >= CONDUCT_WARN_STRIKES && !c.warned:
+		c.warned = true
+		return CONDUCT_WARN
+	}
+
+	return CONDUCT_DROP
+}
+
+// Violation counts by kind, for logs
+func (c *Conduct) Summary() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var parts []string
+	for kind, count := range c.Counts {
+		parts = append(parts, fmt.Sprintf("%s x%d", kind, count))
+	}
+
+	sort.Strings(parts)
+	return strings.Join(parts, ", ")
+}
+
+// Checks an input before it reaches the simulation: sequenced inputs have to keep counting up, and a body's
+// target has to be a real point near the ship. Called by SubmitInput under TickMu.
+func (p *Player) validateInput(input *protocol.Input) (violation *Violation) {
+	if p.Body != nil && input.Flags&protocol.BITFLAG_MOUSE_MOVE != 0 {
+		var x, y float64 = float64(input.MouseX), float64(input.MouseY)
+		if math.IsNaN(x) || math.IsNaN(y) || math.IsInf(x, 0) || math.IsInf(y, 0) {
+			return &Violation{Kind: VIOLATION_TARGET, Detail: fmt.Sprintf("non-finite target (%g, %g)", x, y)}
+		}
+
+		if distance := math.Hypot(x-p.Body.Position.X, y-p.Body.Position.Y); distance > MAX_TARGET_DISTANCE {
+			return &Violation{Kind: VIOLATION_TARGET, Detail: fmt.Sprintf("target %.0f units from the ship", distance)}
+		}
+	}
+
+	if p.Protocol >= protocol.PROTOCOL_PREDICTION_VERSION {
+		if input.Sequence <= p.LastSequence {
+			return &Violation{Kind: VIOLATION_SEQUENCE, Detail: fmt.Sprintf("sequence %d after %d", input.Sequence, p.LastSequence)}
+		}
+
+		p.LastSequence = input.Sequence
+	}
+
+	return
+}
@@ -0,0 +1,60 @@
+package game
+
+import "github.com/z46-dev/game-dev-project/shared/definitions"
+
+func NewConsumableSlot(cfg *definitions.Consumable) (c *ConsumableSlot) {
+	c = &ConsumableSlot{
+		Cfg:     cfg,
+		Charges: cfg.Charges,
+	}
+
+	return
+}
+
+func (c *ConsumableSlot) Ready() (ready bool) {
+	ready = c.Cooldown == 0 && c.Active == 0 && (c.Cfg.Charges == 0 || c.Charges > 0)
+	return
+}
+
+func (c *ConsumableSlot) Activate() (activated bool) {
+	if !c.Ready() {
+		return false
+	}
+
+	if c.Cfg.Charges > 0 {
+		c.Charges--
+	}
+
+	c.Active = c.Cfg.Duration
+	c.Cooldown = c.Cfg.Cooldown
+	return true
+}
+
+func (c *ConsumableSlot) Update(s *Ship) {
+	if c.Active > 0 {
+		c.Active--
+
+		switch c.Cfg.ID {
+		case definitions.CONSUMABLE_REPAIR_PARTY:
+			s.Health.Damage(-s.Health.MaxHealth * c.Cfg.Strength / float64(c.Cfg.Duration))
+		}
+
+		return
+	}
+
+	if c.Cooldown > 0 {
+		c.Cooldown--
+	}
+}
+
+// Combined speed multiplier from every active engine boost
+func (s *Ship) SpeedMultiplier() (multiplier float64) {
+	multiplier = 1
+	for _, c := range s.Consumables {
+		if c.Active > 0 && c.Cfg.ID == definitions.CONSUMABLE_ENGINE_BOOST {
+			multiplier *= c.Cfg.Strength
+		}
+	}
+
+	return
+}
@@ -1,8 +1,7 @@
 package game
 
 import (
-	"math"
-
+	"github.com/z46-dev/game-dev-project/shared/movement"
 	"github.com/z46-dev/game-dev-project/util"
 )
 
@@ -25,17 +24,5 @@ func NewControl(game *Game, body *Ship) (ctrl *Control) {
 }
 
 func (c *Control) Update() {
-	if c.Goal != nil && c.Goal.Magnitude() > 0 {
-		var (
-			angleToGoal      float64 = c.Goal.Direction()
-			delta            float64 = wrapAngle(angleToGoal - c.Body.Rotation)
-			speed, turnSpeed float64 = c.Body.Cfg.Speed / 120, c.Body.Cfg.TurnSpeed //math.Min(c.Body.Cfg.TurnSpeed, util.AngleDifference(c.Body.Rotation, angleToGoal))
-		)
-
-		delta = min(turnSpeed, max(-turnSpeed, delta))
-		c.Body.Rotation += delta
-
-		c.Body.Velocity.X += math.Cos(c.Body.Rotation) * speed
-		c.Body.Velocity.Y += math.Sin(c.Body.Rotation) * speed
-	}
+	movement.Steer(&c.Body.Rotation, c.Body.Velocity, c.Goal, movement.Acceleration(c.Body.Cfg.Speed, c.Body.SpeedMultiplier()), c.Body.Cfg.TurnSpeed)
 }
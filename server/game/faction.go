@@ -16,11 +16,18 @@ var factionColors []color.RGBA = []color.RGBA{
 
 func NewFaction(g *Game, name string) (f *Faction) {
 	g.nextFactionID++
+	f = addFaction(g, g.nextFactionID, name, factionColors[int(g.nextFactionID)%len(factionColors)])
+	return
+}
+
+// Registers a faction under an ID that is already taken care of, as when restoring a snapshot
+func addFaction(g *Game, id uint64, name string, c color.RGBA) (f *Faction) {
 	f = &Faction{
-		ID:               g.nextFactionID,
+		ID:               id,
 		Name:             name,
-		Color:            factionColors[int(g.nextFactionID)%len(factionColors)],
+		Color:            c,
 		ShipsSpatialHash: util.NewSpatialHash[*Ship](),
+		Allies:           make(map[uint64]bool),
 	}
 
 	g.FactionsMu.Lock()
@@ -32,3 +39,22 @@ func NewFaction(g *Game, name string) (f *Faction) {
 func (f *Faction) Update() {
 	f.ShipsSpatialHash.Clear()
 }
+
+// Alliances are symmetric, so both factions are updated
+func (f *Faction) AllyWith(other *Faction) {
+	if other == nil || other == f {
+		return
+	}
+
+	f.Allies[other.ID] = true
+	other.Allies[f.ID] = true
+}
+
+func (f *Faction) IsAlliedWith(other *Faction) (allied bool) {
+	if f == nil || other == nil {
+		return false
+	}
+
+	allied = f == other || f.Allies[other.ID]
+	return
+}
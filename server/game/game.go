@@ -1,8 +1,10 @@
 package game
 
 import (
+	"maps"
 	"math"
 	"math/rand/v2"
+	"slices"
 	"time"
 
 	"github.com/z46-dev/game-dev-project/shared/definitions"
@@ -10,17 +12,32 @@ import (
 	"github.com/z46-dev/game-dev-project/util"
 )
 
+const TPS int = 30 // Simulation ticks per second
+
 func NewGame() (g *Game) {
 	g = &Game{
 		Ships:           util.NewSafeStorage[*Ship](),
+		Islands:         util.NewSafeStorage[*Island](),
 		Planes:          util.NewSafeStorage[*Plane](),
 		spatialHash:     util.NewSpatialHash[CollidableObject](),
 		ShipCache:       make(map[uint64]*ShipCache),
 		ProjectileCache: make(map[uint64]*GenericObjectCache),
 		Players:         make(map[int]*Player),
+		joining:         make(map[int]*Player),
 		Factions:        make(map[uint64]*Faction),
+		Mutes:           NewMuteList(),
+		Settings: RoomSettings{
+			SpectatorFogOfWar: true,
+			SpectatorMaxFOV:   6000,
+			MapExtent:         8192,
+			PositionPrecision: 0.125,
+			MaxRewindTicks:    6,
+			ViewByteBudget:    2048,
+		},
 	}
 
+	g.SetSeed(rand.Uint64())
+	g.AddChatFilter(g.Mutes.Filter())
 	return
 }
 
@@ -32,14 +49,26 @@ func (g *Game) Init() {
 		definitions.ShipParseval,
 	}
 
+	for range 8 {
+		var island *Island = NewIsland(g, util.RandomRadius(g.RNG, 6144), util.RandomRange(g.RNG, 320, 960), util.RandomRange(g.RNG, 0, math.Pi*2), randomIslandPath(g.RNG, util.RandomRangeInt(g.RNG, 9, 16)))
+		g.Islands.Add(island)
+	}
+
+	for i, name := range []string{"Alpha", "Bravo", "Charlie"} {
+		NewObjective(g, name, util.VectorFromAngle(float64(i)/3*math.Pi*2, 2048), 400)
+	}
+
 	var npcFaction *Faction = NewFaction(g, "NPCs")
 	for range 5 {
-		var ship *Ship = NewShip(g, util.RandomRadius(4096), botChoices[rand.IntN(len(botChoices))], npcFaction)
+		var ship *Ship = NewShip(g, util.RandomRadius(g.RNG, 4096), botChoices[g.RNG.IntN(len(botChoices))], npcFaction)
 		g.Ships.Add(ship)
 	}
 }
 
 func (g *Game) Update() {
+	g.TickMu.Lock()
+	defer g.TickMu.Unlock()
+
 	g.time++
 
 	// Update spatial hash
@@ -50,8 +79,22 @@ func (g *Game) Update() {
 
 	// Flush storages
 	g.Ships.Flush()
+	g.Islands.Flush()
 	g.Planes.Flush()
 
+	// Islands never move, but the spatial hash is rebuilt every tick
+	g.Islands.ForEach(func(i *Island) {
+		i.Insert()
+	})
+
+	// Input phase, sequenced inputs are applied one per tick
+	g.PlayersMu.RLock()
+	for _, player := range g.playersInOrder() {
+		player.ConsumeInput()
+		player.Strike(g)
+	}
+	g.PlayersMu.RUnlock()
+
 	// Update ships & projectiles (Update & Insert phase)
 	g.Ships.ForEach(func(s *Ship) {
 		s.Update()
@@ -71,24 +114,79 @@ func (g *Game) Update() {
 		p.Collide()
 	})
 
+	// Death phase
+	var sunk []string
+	g.Ships.ForEach(func(s *Ship) {
+		if !s.Health.IsAlive() {
+			g.Ships.Remove(s)
+			sunk = append(sunk, s.Name)
+		}
+	})
+
+	for _, name := range sunk {
+		g.SystemMessage(name + " was sunk")
+	}
+
+	// History phase, final transforms are what clients are shown this tick
+	g.Ships.ForEach(func(s *Ship) {
+		s.History.Record(g.time, s)
+	})
+
+	g.PlayersMu.RLock()
+	for _, player := range g.playersInOrder() {
+		switch {
+		case player.Body == nil:
+		case player.Body.Health.IsAlive():
+			player.Performance.TimeAlive++
+		default:
+			player.Performance.Deaths++
+			player.Spectate(g)
+		}
+	}
+	g.PlayersMu.RUnlock()
+
+	// Vision phase
+	g.updateVision()
+
+	// Objectives phase
+	for _, o := range g.Objectives {
+		o.Update(g)
+	}
+
+	if g.Settings.MatchLength > 0 && g.time-g.roundStart >= g.Settings.MatchLength {
+		g.endRound()
+	}
+
 	for _, player := range g.Players {
-		var w *protocol.Writer = new(protocol.Writer)
+		var w *protocol.Writer = player.NewWriter()
 		w.SetU8(protocol.PACKET_CLIENTBOUND_VIEW_UPDATE)
 		player.Camera.See(g, player, w)
 		player.Socket.Write(w.GetBytes())
+		player.SendGUIUpdate(g)
+
+		if g.time%protocol.MAP_UPDATE_INTERVAL == 0 {
+			var m *protocol.Writer = player.NewWriter()
+			m.SetU8(protocol.PACKET_CLIENTBOUND_MAP_UPDATE)
+			player.WriteMapUpdate(g, m)
+			player.Socket.Write(m.GetBytes())
+		}
 	}
-}
 
-func wrapAngle(angle float64) float64 {
-	for angle > math.Pi {
-		angle -= math.Pi * 2
+	if g.InputLog != nil && g.time%INPUT_LOG_HASH_INTERVAL == 0 {
+		g.InputLog.checkpoint(g.time, g.StateHash())
 	}
+}
 
-	for angle < -math.Pi {
-		angle += math.Pi * 2
+// Players by socket ID. Anything the simulation does to each player goes in this order, so it comes out the
+// same in every run. Call with PlayersMu held.
+func (g *Game) playersInOrder() (players []*Player) {
+	var ids []int = slices.Sorted(maps.Keys(g.Players))
+	players = make([]*Player, len(ids))
+	for i, id := range ids {
+		players[i] = g.Players[id]
 	}
 
-	return angle
+	return
 }
 
 func (g *Game) BeginUpdateLoop(tps int) {
@@ -110,16 +208,36 @@ func SetPlayerInput(g *Game, socketID int, flags uint8) {
 }
 
 func RemovePlayer(g *Game, socketID int) {
-	g.PlayersMu.Lock()
-	player := g.Players[socketID]
-	delete(g.Players, socketID)
-	g.PlayersMu.Unlock()
+	g.external(LogEvent{Kind: LOG_EVENT_LEAVE, Player: socketID}, func() {
+		delete(g.joining, socketID)
 
-	if player == nil {
-		return
-	}
+		g.PlayersMu.Lock()
+		player := g.Players[socketID]
+		delete(g.Players, socketID)
+		g.PlayersMu.Unlock()
+
+		if player != nil {
+			g.leave(player)
+		}
+	})
+}
 
+// Takes a player who is already out of Players out of the world and reports their session. Call under TickMu.
+func (g *Game) leave(player *Player) {
 	if player.Body != nil {
 		g.Ships.Remove(player.Body)
 	}
+
+	// Open-ended matches have no rounds to report in, the session is the match
+	var line PlayerSummary = player.summary()
+	g.settle(player)
+	if player.Spawned != nil {
+		if g.Settings.MatchLength > 0 {
+			g.departed = append(g.departed, line)
+		} else if g.OnMatchEnd != nil {
+			g.OnMatchEnd(&MatchSummary{Started: line.Since, Ended: g.time, Players: []PlayerSummary{line}})
+		}
+	}
+
+	g.SystemMessage(player.Name + " left the game")
 }
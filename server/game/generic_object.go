@@ -1,6 +1,7 @@
 package game
 
 import (
+	"github.com/z46-dev/game-dev-project/shared/movement"
 	"github.com/z46-dev/game-dev-project/util"
 )
 
@@ -17,7 +18,7 @@ func NewGameObject(game *Game, position *util.Vector2D, f *Faction) (o *GenericO
 	o.Velocity = util.Vector(0, 0)
 	o.Size = 32
 	o.Rotation = 0
-	o.Friction = 0.95
+	o.Friction = movement.DEFAULT_FRICTION
 	o.Density = 1
 	o.Pushability = 1
 	o.Faction = f
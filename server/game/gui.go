@@ -0,0 +1,73 @@
+package game
+
+import (
+	"bytes"
+
+	"github.com/z46-dev/game-dev-project/shared/movement"
+	"github.com/z46-dev/game-dev-project/shared/protocol"
+)
+
+// Timers are sent in tenths of a second so the payload only changes a few times per second
+func ticksToDeciseconds(ticks int) uint16 {
+	return uint16(min(ticks*10/TPS, 0xFFFF))
+}
+
+func (p *Player) WriteGUIUpdate(g *Game, w *protocol.Writer) {
+	var update *protocol.GUIUpdate = &protocol.GUIUpdate{
+		Score:       uint32(p.Score),
+		Elapsed:     uint32((g.time - g.roundStart) / TPS),
+		MatchLength: uint32(g.Settings.MatchLength / TPS),
+	}
+
+	if p.Body != nil {
+		update.HasBody = 1
+		update.ShipID = uint8(p.Body.Cfg.ID)
+		update.Health = float32(p.Body.Health.Health)
+		update.MaxHealth = float32(p.Body.Health.MaxHealth)
+
+		// Units per second, the top speed being where thrust and friction cancel out
+		update.Speed = float32(p.Body.Velocity.Magnitude() * float64(TPS))
+		update.MaxSpeed = float32(movement.Acceleration(p.Body.Cfg.Speed, p.Body.SpeedMultiplier()) / (1 - p.Body.Friction) * float64(TPS))
+
+		update.Squadrons = make([]protocol.GUISquadron, len(p.Body.Hangar))
+		for i, h := range p.Body.Hangar {
+			update.Squadrons[i] = protocol.GUISquadron{
+				PlaneID:     uint16(h.Cfg.Plane.ID),
+				Planes:      uint8(h.Planes),
+				HangarSize:  uint8(h.Cfg.HangarSize),
+				LaunchTimer: ticksToDeciseconds(h.LaunchTimer),
+				RegenTimer:  ticksToDeciseconds(h.RegenTimer),
+			}
+		}
+
+		update.Consumables = make([]protocol.GUIConsumable, len(p.Body.Consumables))
+		for i, c := range p.Body.Consumables {
+			update.Consumables[i] = protocol.GUIConsumable{
+				ConsumableID: uint8(c.Cfg.ID),
+				Charges:      uint8(c.Charges),
+				Cooldown:     ticksToDeciseconds(c.Cooldown),
+				Active:       ticksToDeciseconds(c.Active),
+			}
+
+			if c.Cfg.Charges == 0 {
+				update.Consumables[i].Charges = 0xFF
+			}
+		}
+	}
+
+	update.Write(w)
+}
+
+// Only sends the GUI state when something on it changed since the last update
+func (p *Player) SendGUIUpdate(g *Game) {
+	var w *protocol.Writer = p.NewWriter()
+	w.SetU8(protocol.PACKET_CLIENTBOUND_GUI_UPDATE)
+	p.WriteGUIUpdate(g, w)
+
+	if bytes.Equal(w.GetBytes(), p.lastGUI) {
+		return
+	}
+
+	p.lastGUI = w.GetBytes()
+	p.Socket.Write(p.lastGUI)
+}
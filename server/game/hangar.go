@@ -0,0 +1,31 @@
+package game
+
+import "github.com/z46-dev/game-dev-project/shared/definitions"
+
+func NewHangarSquadron(cfg *definitions.Squadron) (h *HangarSquadron) {
+	h = &HangarSquadron{
+		Cfg:    cfg,
+		Planes: cfg.HangarSize,
+	}
+
+	return
+}
+
+func (h *HangarSquadron) Update() {
+	if h.LaunchTimer > 0 {
+		h.LaunchTimer--
+	}
+
+	if h.Cfg.PlaneRegenerationTime <= 0 || h.Planes >= h.Cfg.HangarSize {
+		h.RegenTimer = 0
+		return
+	}
+
+	if h.RegenTimer == 0 {
+		h.RegenTimer = h.Cfg.PlaneRegenerationTime
+	}
+
+	if h.RegenTimer--; h.RegenTimer == 0 {
+		h.Planes++
+	}
+}
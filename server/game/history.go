@@ -0,0 +1,106 @@
+package game
+
+import (
+	"math"
+
+	"github.com/z46-dev/game-dev-project/util"
+)
+
+const TRANSFORM_HISTORY_SIZE int = TPS // One second, the most a shot can ever be rewound
+
+func (h *TransformHistory) Record(tick int, s *Ship) {
+	h.entries[tick%TRANSFORM_HISTORY_SIZE] = Transform{
+		Tick:     tick,
+		X:        s.Position.X,
+		Y:        s.Position.Y,
+		Size:     s.Size,
+		Rotation: s.Rotation,
+	}
+}
+
+// The transform recorded at tick, false if it has been overwritten or the ship did not exist yet
+func (h *TransformHistory) At(tick int) (t Transform, ok bool) {
+	if tick < 0 {
+		return
+	}
+
+	t = h.entries[tick%TRANSFORM_HISTORY_SIZE]
+	ok = t.Tick == tick && t.Size > 0
+	return
+}
+
+// The ship's hull as it was at the end of tick, nil when that is not in its history. The current tick (or
+// later) is the live polygon.
+func (s *Ship) PolygonAt(tick int) *util.Polygon {
+	if tick >= s.Game.time {
+		return s.Polygon
+	}
+
+	t, ok := s.History.At(tick)
+	if !ok {
+		return nil
+	}
+
+	return util.NewPolygon(s.Cfg.HullPath, util.Vector(t.X, t.Y), t.Size/2, t.Rotation)
+}
+
+// Tick a player's aimed shots are checked at: what their client was drawing, but never further back than
+// the rewind window so a lagging (or lying) client can't hit where ships were long ago
+func (p *Player) ShotTick(g *Game) int {
+	if p.ViewTick <= 0 {
+		return g.time
+	}
+
+	return min(g.time, max(g.time-g.Settings.MaxRewindTicks, p.ViewTick))
+}
+
+// Ships not allied with the shooter that could be hit at tick, with their hull at that tick. A shooter
+// without a body has nothing to shoot with.
+func (g *Game) rewoundTargets(shooter *Player, tick int, each func(s *Ship, hull *util.Polygon)) {
+	if shooter.Body == nil {
+		return
+	}
+
+	g.Ships.ForEach(func(s *Ship) {
+		if shooter.Body.Faction.IsAlliedWith(s.Faction) {
+			return
+		}
+
+		if hull := s.PolygonAt(tick); hull != nil {
+			each(s, hull)
+		}
+	})
+}
+
+// Resolves a player aimed shot along a segment against ships as the player saw them. Returns the hit
+// ship closest to from, or nil on a miss.
+func (g *Game) AimedShot(shooter *Player, from, to *util.Vector2D) (hit *Ship) {
+	var (
+		bounds  *util.AABB = &util.AABB{X1: min(from.X, to.X), Y1: min(from.Y, to.Y), X2: max(from.X, to.X), Y2: max(from.Y, to.Y)}
+		closest float64    = math.Inf(1)
+	)
+
+	g.rewoundTargets(shooter, shooter.ShotTick(g), func(s *Ship, hull *util.Polygon) {
+		if !hull.AABB.Intersects(bounds) || !hull.SegmentIntersects(from, to) {
+			return
+		}
+
+		var center *util.Vector2D = hull.AABB.GetCenter()
+		if distance := math.Hypot(center.X-from.X, center.Y-from.Y); distance < closest {
+			hit, closest = s, distance
+		}
+	})
+
+	return
+}
+
+// Resolves a player aimed area effect (bombs, reticles) against ships as the player saw them
+func (g *Game) AimedArea(shooter *Player, center *util.Vector2D, radius float64) (hits []*Ship) {
+	g.rewoundTargets(shooter, shooter.ShotTick(g), func(s *Ship, hull *util.Polygon) {
+		if hull.CircleIntersects(center, radius) {
+			hits = append(hits, s)
+		}
+	})
+
+	return
+}
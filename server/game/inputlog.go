@@ -0,0 +1,376 @@
+package game
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"math/rand/v2"
+	"os"
+	"time"
+
+	"github.com/z46-dev/game-dev-project/server/web"
+	"github.com/z46-dev/game-dev-project/shared/definitions"
+	"github.com/z46-dev/game-dev-project/shared/movement"
+	"github.com/z46-dev/game-dev-project/shared/protocol"
+)
+
+const (
+	LOG_EVENT_JOIN       uint8 = iota // NewPlayer
+	LOG_EVENT_OBSERVE                 // NewObserver
+	LOG_EVENT_REGISTER                // Player.Register, carries the negotiated protocol and the account
+	LOG_EVENT_LEAVE                   // RemovePlayer
+	LOG_EVENT_INPUT                   // SubmitInput
+	LOG_EVENT_CONSUMABLE              // ActivateConsumable
+	LOG_EVENT_SPECTATE                // Spectate
+)
+
+const (
+	INPUT_LOG_HASH_INTERVAL int = TPS     // Ticks between state hashes, the log is flushed with each
+	MAX_INPUT_LOG_LINE      int = 1 << 20 // Longest line a reader accepts
+)
+
+// Seeds the simulation RNG. Call before Init, a replay seeded the same way draws the same numbers.
+func (g *Game) SetSeed(seed uint64) {
+	g.Seed = seed
+	g.rngState = rand.NewPCG(seed, seed^0x9e3779b97f4a7c15)
+	g.RNG = rand.New(g.rngState)
+}
+
+// Ticks completed so far
+func (g *Game) Time() int {
+	return g.time
+}
+
+// Makes a change from outside the simulation between two ticks and logs it, so a replay can make it at
+// the same point
+func (g *Game) external(event LogEvent, apply func()) {
+	g.TickMu.Lock()
+	defer g.TickMu.Unlock()
+
+	g.record(event)
+	apply()
+}
+
+// Logs a change made from outside at the current tick. Call under TickMu.
+func (g *Game) record(event LogEvent) {
+	event.Tick = g.time
+	g.InputLog.write(&LogLine{Event: &event})
+}
+
+// Hands an input to the simulation once it passes validation, checked in the same step so nothing can change
+// in between. Bodies on v4+ take one per tick from a queue, older clients steer right away, and without a body
+// the keys drive the spectator camera. A rejected input is dropped without being logged.
+func (g *Game) SubmitInput(p *Player, input protocol.Input) (violation *Violation) {
+	g.TickMu.Lock()
+	defer g.TickMu.Unlock()
+
+	if violation = p.validateInput(&input); violation != nil {
+		return
+	}
+
+	g.record(LogEvent{Kind: LOG_EVENT_INPUT, Player: p.Socket.ID, Input: &input})
+	switch {
+	case p.Body == nil:
+		if p.Spectator != nil {
+			p.Spectator.Goal = movement.Goal(input.Flags)
+		}
+	case p.Protocol >= protocol.PROTOCOL_PREDICTION_VERSION:
+		p.QueueInput(input)
+	default:
+		p.ApplyInput(&input)
+	}
+
+	return
+}
+
+func (g *Game) ActivateConsumable(p *Player, slot uint8) {
+	g.external(LogEvent{Kind: LOG_EVENT_CONSUMABLE, Player: p.Socket.ID, Slot: slot}, func() {
+		if p.Body != nil && int(slot) < len(p.Body.Consumables) {
+			p.Body.Consumables[slot].Activate()
+		}
+	})
+}
+
+// Applies a spectator camera action. Cycling walks g.Ships and the tick reads the camera, so it waits for
+// TickMu like everything else from a socket.
+func (g *Game) Spectate(p *Player, action protocol.SpectateAction) {
+	g.external(LogEvent{Kind: LOG_EVENT_SPECTATE, Player: p.Socket.ID, Spectate: &action}, func() {
+		if p.Spectator != nil {
+			p.Spectator.HandleAction(g, &action)
+		}
+	})
+}
+
+// Creates the log and writes the header. Call once the world is built, by Init or from restored, before the
+// first tick.
+func CreateInputLog(path string, g *Game, restored *Snapshot) (l *InputLog, err error) {
+	var file *os.File
+	if file, err = os.Create(path); err != nil {
+		return
+	}
+
+	l = &InputLog{file: file, writer: bufio.NewWriter(file)}
+	l.encoder = json.NewEncoder(l.writer)
+
+	if err = l.encoder.Encode(&LogLine{Header: &InputLogHeader{
+		Seed:            g.Seed,
+		Settings:        g.Settings,
+		TPS:             TPS,
+		DefinitionsHash: definitions.Hash(),
+		Started:         time.Now(),
+		Snapshot:        restored,
+	}}); err == nil {
+		err = l.writer.Flush()
+	}
+
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return
+}
+
+func (l *InputLog) write(line *LogLine) {
+	if l == nil || l.err != nil {
+		return
+	}
+
+	if l.err = l.encoder.Encode(line); l.err != nil && l.OnError != nil {
+		l.OnError(l.err)
+	}
+}
+
+func (l *InputLog) checkpoint(tick int, hash uint64) {
+	l.write(&LogLine{Checkpoint: &LogCheckpoint{Tick: tick, Hash: hash}})
+	if l.err != nil {
+		return
+	}
+
+	if l.err = l.writer.Flush(); l.err != nil && l.OnError != nil {
+		l.OnError(l.err)
+	}
+}
+
+func (l *InputLog) Close() error {
+	if l.err == nil {
+		l.err = l.writer.Flush()
+	}
+
+	if err := l.file.Close(); l.err == nil {
+		l.err = err
+	}
+
+	return l.err
+}
+
+// Opens a log and reads its header
+func OpenInputLog(path string) (r *InputLogReader, err error) {
+	var file *os.File
+	if file, err = os.Open(path); err != nil {
+		return
+	}
+
+	r = &InputLogReader{file: file, scanner: bufio.NewScanner(file)}
+	r.scanner.Buffer(nil, MAX_INPUT_LOG_LINE)
+
+	var line LogLine
+	if line, err = r.Next(); err == nil && line.Header == nil {
+		err = fmt.Errorf("%s does not start with an input log header", path)
+	}
+
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	r.Header = *line.Header
+	return
+}
+
+// The next line, io.EOF once there are none
+func (r *InputLogReader) Next() (line LogLine, err error) {
+	if !r.scanner.Scan() {
+		if err = r.scanner.Err(); err == nil {
+			err = io.EOF
+		}
+
+		return
+	}
+
+	err = json.Unmarshal(r.scanner.Bytes(), &line)
+	return
+}
+
+func (r *InputLogReader) Close() error {
+	return r.file.Close()
+}
+
+// FNV-1a over everything a tick can change, in a fixed order. Two runs that agree on it agree on the simulation.
+func (g *Game) StateHash() uint64 {
+	var buffer []byte
+	var put = func(values ...float64) {
+		for _, value := range values {
+			buffer = binary.LittleEndian.AppendUint64(buffer, math.Float64bits(value))
+		}
+	}
+
+	var factionID = func(f *Faction) float64 {
+		if f == nil {
+			return 0
+		}
+
+		return float64(f.ID)
+	}
+
+	put(float64(g.time), float64(g.roundStart), float64(g.nextID), float64(g.nextFactionID))
+	if state, err := g.rngState.MarshalBinary(); err == nil {
+		buffer = append(buffer, state...)
+	}
+
+	g.Ships.ForEach(func(s *Ship) {
+		put(float64(s.ID), factionID(s.Faction), s.Position.X, s.Position.Y, s.Velocity.X, s.Velocity.Y, s.Rotation, s.Size, s.Health.Health)
+		put(s.Control.Goal.X, s.Control.Goal.Y)
+		if target := s.Control.PrimaryTarget; target != nil {
+			put(target.X, target.Y)
+		}
+
+		for _, h := range s.Hangar {
+			put(float64(h.Planes), float64(h.LaunchTimer), float64(h.RegenTimer))
+		}
+
+		for _, c := range s.Consumables {
+			put(float64(c.Charges), float64(c.Cooldown), float64(c.Active))
+		}
+	})
+
+	g.Planes.ForEach(func(p *Plane) {
+		put(float64(p.ID), p.Position.X, p.Position.Y, p.Velocity.X, p.Velocity.Y)
+	})
+
+	for _, o := range g.Objectives {
+		put(factionID(o.Owner), factionID(o.Capturer), o.Progress)
+	}
+
+	g.PlayersMu.RLock()
+	for _, p := range g.playersInOrder() {
+		var body float64
+		if p.Body != nil {
+			body = float64(p.Body.ID)
+		}
+
+		put(float64(p.Socket.ID), float64(p.Score), body, float64(p.InputAck), float64(len(p.Inputs)))
+	}
+	g.PlayersMu.RUnlock()
+
+	var h = fnv.New64a()
+	h.Write(buffer)
+	return h.Sum64()
+}
+
+// Rebuilds the room a log was recorded in as it was before the first logged tick
+func NewReplay(header *InputLogHeader) (r *Replay, err error) {
+	r = &Replay{Game: NewGame(), players: make(map[int]*Player)}
+	r.Game.Settings = header.Settings
+
+	if header.Snapshot != nil {
+		if err = r.Game.Restore(header.Snapshot); err != nil {
+			return nil, err
+		}
+
+		return
+	}
+
+	r.Game.SetSeed(header.Seed)
+	r.Game.Init()
+	return
+}
+
+// Runs ticks until tick of them have completed
+func (r *Replay) AdvanceTo(tick int) (err error) {
+	if tick < r.Game.time {
+		return fmt.Errorf("log goes back from tick %d to %d", r.Game.time, tick)
+	}
+
+	for r.Game.time < tick {
+		r.Game.Update()
+	}
+
+	return
+}
+
+// Makes a logged change at the point it was made live. Players have no connection, what they are sent is dropped.
+func (r *Replay) Apply(e *LogEvent) (err error) {
+	if err = r.AdvanceTo(e.Tick); err != nil {
+		return
+	}
+
+	var player *Player = r.players[e.Player]
+	switch e.Kind {
+	case LOG_EVENT_JOIN:
+		def, found := definitions.GetByKey(definitions.ShipConfigs, definitions.ShipID(e.ShipID))
+		if !found {
+			return fmt.Errorf("tick %d: unknown ship %d", e.Tick, e.ShipID)
+		}
+
+		if _, err = def.Loadout(e.Loadout); err != nil {
+			return fmt.Errorf("tick %d: %w", e.Tick, err)
+		}
+
+		r.players[e.Player] = NewPlayer(r.Game, web.NewDetachedSocket(e.Player), e.Name, def, e.Loadout)
+		return
+	case LOG_EVENT_OBSERVE:
+		r.players[e.Player] = NewObserver(r.Game, web.NewDetachedSocket(e.Player), e.Name)
+		return
+	case LOG_EVENT_LEAVE:
+		// Connections that never joined leave too. The player is kept, a join that was still being accepted
+		// logs its register after the leave and has to find them.
+		RemovePlayer(r.Game, e.Player)
+		return
+	}
+
+	if player == nil {
+		return fmt.Errorf("tick %d: event %d for player #%d, who never joined", e.Tick, e.Kind, e.Player)
+	}
+
+	switch e.Kind {
+	case LOG_EVENT_REGISTER:
+		player.Protocol = e.Protocol
+		player.AccountID = e.Account
+		player.Register(r.Game)
+	case LOG_EVENT_INPUT:
+		if e.Input == nil {
+			return fmt.Errorf("tick %d: input event without an input", e.Tick)
+		}
+
+		if violation := r.Game.SubmitInput(player, *e.Input); violation != nil {
+			return fmt.Errorf("tick %d: logged input rejected, %v", e.Tick, violation)
+		}
+	case LOG_EVENT_CONSUMABLE:
+		r.Game.ActivateConsumable(player, e.Slot)
+	case LOG_EVENT_SPECTATE:
+		if e.Spectate == nil {
+			return fmt.Errorf("tick %d: spectate event without an action", e.Tick)
+		}
+
+		r.Game.Spectate(player, *e.Spectate)
+	default:
+		return fmt.Errorf("tick %d: unknown event %d", e.Tick, e.Kind)
+	}
+
+	return
+}
+
+// Runs up to the checkpoint's tick and compares hashes
+func (r *Replay) Verify(c *LogCheckpoint) (hash uint64, ok bool, err error) {
+	if err = r.AdvanceTo(c.Tick); err != nil {
+		return
+	}
+
+	hash = r.Game.StateHash()
+	return hash, hash == c.Hash, nil
+}
@@ -0,0 +1,109 @@
+package game
+
+import (
+	"math"
+	"sort"
+
+	"github.com/z46-dev/game-dev-project/shared/protocol"
+	"github.com/z46-dev/game-dev-project/util"
+)
+
+const (
+	INTEREST_MIN_PRIORITY       float64 = 0.1 // Even the least interesting ship builds up priority and is eventually sent
+	INTEREST_REFERENCE_SIZE     float64 = 200 // Ships this size score 1 for size, bigger ones more
+	INTEREST_OWN_FACTION_WEIGHT float64 = 2
+	INTEREST_TARGET_WEIGHT      float64 = 4
+	INTEREST_THREAT_WEIGHT      float64 = 4
+)
+
+// Whether the player is aiming at (or spectating) a ship
+func (c *Camera) isTarget(player *Player, o *Ship) bool {
+	if player.Spectator != nil {
+		return player.Spectator.TargetID == o.ID
+	}
+
+	if player.Body == nil || player.Body.Control.PrimaryTarget == nil {
+		return false
+	}
+
+	var target = player.Body.Control.PrimaryTarget
+	return o.Polygon.AABB.Contains(target.X, target.Y)
+}
+
+// Whether an enemy ship has a strike in the air aimed close enough to the player's body to hit it
+func (c *Camera) isThreat(player *Player, o *Ship) (threat bool) {
+	if player.Body == nil || player.Body.Faction.IsAlliedWith(o.Faction) {
+		return false
+	}
+
+	o.Game.Planes.ForEach(func(p *Plane) {
+		if p.Carrier != o || threat {
+			return
+		}
+
+		var reach float64 = player.Body.Size + p.Ordnance.Radius + p.Ordnance.Length
+		threat = util.SquaredDistance(p.Target, player.Body.Position) <= reach*reach
+	})
+
+	return
+}
+
+// How much a player needs to hear about a ship this tick. Close, big, friendly, targeted and threatening
+// ships matter most; the camera adds this up every tick a ship is held back, so stale ones rise until they
+// get through.
+func (c *Camera) shipPriority(player *Player, o *Ship) (priority float64) {
+	var distance float64 = math.Hypot(o.Position.X-c.Position.X, o.Position.Y-c.Position.Y)
+	priority = max(INTEREST_MIN_PRIORITY, 1-distance/c.FOV) * math.Sqrt(o.Size/INTEREST_REFERENCE_SIZE)
+
+	if o.Faction == player.Faction {
+		priority *= INTEREST_OWN_FACTION_WEIGHT
+	}
+
+	if c.isTarget(player, o) {
+		priority *= INTEREST_TARGET_WEIGHT
+	}
+
+	if c.isThreat(player, o) {
+		priority *= INTEREST_THREAT_WEIGHT
+	}
+
+	return
+}
+
+// Writes ships in order of accumulated priority until the view update reaches budget bytes. The player's
+// own ship and the first ship always go out so nothing stalls. A held back ship keeps its priority, and if
+// the client already knows it, it is returned as deferred so its silence isn't taken to mean it stopped.
+// Creates are only recorded as seen once written, so a held back create is simply sent on a later tick.
+func (c *Camera) seeShipsBudgeted(player *Player, w *protocol.Writer, ships []*Ship, budget int) (deferred []uint64) {
+	var own uint64
+	if player.Body != nil {
+		own = player.Body.ID
+	}
+
+	for _, o := range ships {
+		c.Priority[o.ID] += c.shipPriority(player, o)
+	}
+
+	sort.Slice(ships, func(i, j int) bool {
+		if (ships[i].ID == own) != (ships[j].ID == own) {
+			return ships[i].ID == own
+		}
+
+		return c.Priority[ships[i].ID] > c.Priority[ships[j].ID]
+	})
+
+	for i, o := range ships {
+		if i > 0 && o.ID != own && len(w.Bytes) >= budget {
+			if c.ShipsSeen[o.ID] {
+				deferred = append(deferred, o.ID)
+			}
+
+			continue
+		}
+
+		c.SeeShip(w, o)
+		c.Priority[o.ID] = 0
+	}
+
+	return
+}
@@ -0,0 +1,44 @@
+package game
+
+import (
+	"math"
+	"math/rand/v2"
+
+	"github.com/z46-dev/game-dev-project/util"
+)
+
+func NewIsland(g *Game, position *util.Vector2D, size, rotation float64, path []*util.Vector2D) (i *Island) {
+	i = &Island{}
+	i.GenericObject = *NewGameObject(g, position, nil)
+	i.Size = size
+	i.Rotation = rotation
+	i.Pushability = 0
+	i.Polygon = util.NewPolygon(path, i.Position, i.Size/2, i.Rotation)
+
+	return
+}
+
+// Builds a rough, star-shaped coastline normalized to -1 to 1
+func randomIslandPath(rng *rand.Rand, numPoints int) (path []*util.Vector2D) {
+	path = make([]*util.Vector2D, numPoints)
+	for i := range numPoints {
+		var (
+			angle  float64 = float64(i) / float64(numPoints) * math.Pi * 2
+			radius float64 = util.RandomRange(rng, 0.6, 1)
+		)
+
+		path[i] = util.VectorFromAngle(angle, radius)
+	}
+
+	return
+}
+
+func (i *Island) GetAABB() (aabb *util.AABB) {
+	aabb = i.Polygon.AABB
+	return
+}
+
+func (i *Island) Insert() {
+	i.Polygon.Transform(i.Position, i.Size/2, i.Rotation)
+	i.Game.spatialHash.Insert(i)
+}
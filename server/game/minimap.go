@@ -0,0 +1,122 @@
+package game
+
+import (
+	"sort"
+
+	"github.com/z46-dev/game-dev-project/shared/protocol"
+	"github.com/z46-dev/game-dev-project/util"
+)
+
+func mapFactionID(f *Faction) uint32 {
+	if f == nil {
+		return 0
+	}
+
+	return uint32(f.ID)
+}
+
+func mapPoint(position *util.Vector2D, extent float64) protocol.MapPoint {
+	return protocol.MapPoint{
+		X: protocol.QuantizeCoordinate(position.X, extent),
+		Y: protocol.QuantizeCoordinate(position.Y, extent),
+	}
+}
+
+// Coarse, low-rate overview of everything the player's faction knows about
+func (p *Player) WriteMapUpdate(g *Game, w *protocol.Writer) {
+	var (
+		extent float64             = g.Settings.MapExtent
+		update *protocol.MapUpdate = &protocol.MapUpdate{
+			Time:   uint32(g.time),
+			Extent: float32(extent),
+		}
+	)
+
+	// Static obstacles only need to be sent once
+	if !p.SentMapStatic {
+		p.SentMapStatic = true
+		update.HasStatic = 1
+
+		g.Islands.ForEach(func(i *Island) {
+			var island protocol.MapIsland = protocol.MapIsland{Points: make([]protocol.MapPoint, len(i.Polygon.Points))}
+			for j, point := range i.Polygon.Points {
+				island.Points[j] = mapPoint(point, extent)
+			}
+
+			update.Islands = append(update.Islands, island)
+		})
+	}
+
+	var (
+		ships    []*Ship
+		factions map[uint64]*Faction = make(map[uint64]*Faction)
+	)
+
+	g.Ships.ForEach(func(s *Ship) {
+		if !s.Health.IsAlive() || !p.CanSee(g, s) {
+			return
+		}
+
+		ships = append(ships, s)
+		if s.Faction != nil {
+			factions[s.Faction.ID] = s.Faction
+		}
+	})
+
+	for _, o := range g.Objectives {
+		if o.Owner != nil {
+			factions[o.Owner.ID] = o.Owner
+		}
+
+		if o.Capturer != nil {
+			factions[o.Capturer.ID] = o.Capturer
+		}
+	}
+
+	sort.Slice(ships, func(i, j int) bool {
+		return ships[i].ID < ships[j].ID
+	})
+
+	// Faction table, referenced by ID below
+	for _, f := range factions {
+		update.Factions = append(update.Factions, protocol.MapFaction{
+			ID:   uint32(f.ID),
+			R:    f.Color.R,
+			G:    f.Color.G,
+			B:    f.Color.B,
+			Name: f.Name,
+		})
+	}
+
+	for _, o := range g.Objectives {
+		update.Objectives = append(update.Objectives, protocol.MapObjective{
+			Position: mapPoint(o.Position, extent),
+			Radius:   float32(o.Radius),
+			Owner:    mapFactionID(o.Owner),
+			Capturer: mapFactionID(o.Capturer),
+			Progress: uint8(o.Progress * 255),
+			Name:     o.Name,
+		})
+	}
+
+	for _, s := range ships {
+		var flags uint8
+		if s == p.Body {
+			flags |= protocol.BITFLAG_MAP_SHIP_SELF
+		}
+
+		if p.Faction.IsAlliedWith(s.Faction) {
+			flags |= protocol.BITFLAG_MAP_SHIP_ALLIED
+		}
+
+		update.Ships = append(update.Ships, protocol.MapShip{
+			Position:       mapPoint(s.Position, extent),
+			Rotation:       protocol.QuantizeAngle8(s.Rotation),
+			Faction:        mapFactionID(s.Faction),
+			Classification: uint8(s.Cfg.Classification),
+			Flags:          flags,
+		})
+	}
+
+	update.Write(w)
+}
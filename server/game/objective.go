@@ -0,0 +1,82 @@
+package game
+
+import (
+	"github.com/z46-dev/game-dev-project/util"
+)
+
+const (
+	OBJECTIVE_CAPTURE_RATE  float64 = 1.0 / (30 * 20) // Progress per tick, a full capture takes 20 seconds
+	OBJECTIVE_DECAY_RATE    float64 = 1.0 / (30 * 10) // Progress lost per tick once the capturer leaves
+	OBJECTIVE_CAPTURE_SCORE int     = 100
+)
+
+func NewObjective(g *Game, name string, position *util.Vector2D, radius float64) (o *Objective) {
+	g.nextID++
+	o = &Objective{
+		ID:       g.nextID,
+		Name:     name,
+		Position: position,
+		Radius:   radius,
+	}
+
+	g.Objectives = append(g.Objectives, o)
+	return
+}
+
+func (o *Objective) Update(g *Game) {
+	var (
+		present   *Faction
+		contested bool
+	)
+
+	for _, something := range g.spatialHash.RetrieveAround(o.Position.X, o.Position.Y, o.Radius) {
+		ship, ok := something.(*Ship)
+		if !ok || !ship.Health.IsAlive() || util.SquaredDistance(ship.Position, o.Position) > o.Radius*o.Radius {
+			continue
+		}
+
+		if present == nil {
+			present = ship.Faction
+		} else if !present.IsAlliedWith(ship.Faction) {
+			contested = true
+			break
+		}
+	}
+
+	// Contested zones are frozen
+	if contested {
+		return
+	}
+
+	if present == nil || present.IsAlliedWith(o.Owner) {
+		o.Progress = max(0, o.Progress-OBJECTIVE_DECAY_RATE)
+		if o.Progress == 0 {
+			o.Capturer = nil
+		}
+
+		return
+	}
+
+	if !present.IsAlliedWith(o.Capturer) {
+		o.Capturer = present
+		o.Progress = 0
+	}
+
+	if o.Progress += OBJECTIVE_CAPTURE_RATE; o.Progress >= 1 {
+		o.Owner = o.Capturer
+		o.Capturer = nil
+		o.Progress = 0
+
+		g.PlayersMu.RLock()
+		for _, player := range g.Players {
+			if player.Faction.IsAlliedWith(o.Owner) {
+				player.Score += OBJECTIVE_CAPTURE_SCORE
+				player.Performance.Captures++
+				player.Performance.Score += int64(OBJECTIVE_CAPTURE_SCORE)
+			}
+		}
+		g.PlayersMu.RUnlock()
+
+		g.SystemMessage(o.Owner.Name + " captured " + o.Name)
+	}
+}
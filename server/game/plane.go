@@ -0,0 +1,66 @@
+package game
+
+import (
+	"github.com/z46-dev/game-dev-project/shared/definitions"
+	"github.com/z46-dev/game-dev-project/shared/movement"
+	"github.com/z46-dev/game-dev-project/util"
+)
+
+const PLANE_MAX_FLIGHT int = 60 * TPS // A flight still in the air after this long is lost
+
+// Takes off from the carrier already heading for the target
+func NewPlane(g *Game, carrier *Ship, cfg *definitions.Squadron, target *util.Vector2D, attackers int, ordnance Ordnance) (p *Plane) {
+	p = &Plane{
+		Cfg:       cfg,
+		Carrier:   carrier,
+		Target:    target,
+		Attackers: attackers,
+		Ordnance:  ordnance,
+	}
+
+	p.GenericObject = *NewGameObject(g, carrier.Position.Copy(), carrier.Faction)
+	p.AABB = &util.AABB{}
+	p.Size = cfg.Plane.Size
+	p.Rotation = target.Copy().Subtract(carrier.Position).Direction()
+	return
+}
+
+// Planes fly over everything, so they stay out of the spatial hash
+func (p *Plane) Update() {
+	if p.Flight++; p.Flight > PLANE_MAX_FLIGHT || p.Game.Ships.Get(p.Carrier.ID) == nil {
+		p.Game.Planes.Remove(p)
+		return
+	}
+
+	movement.Steer(&p.Rotation, p.Velocity, p.Target.Copy().Subtract(p.Position), movement.Acceleration(p.Cfg.Plane.Speed, 1), p.Cfg.Plane.TurnSpeed)
+	movement.Integrate(p.Position, p.Velocity, p.Friction)
+
+	if util.SquaredDistance(p.Position, p.Target) <= max(p.Ordnance.Release*p.Ordnance.Release, p.Velocity.SquaredMagnitude()) {
+		p.Drop()
+	}
+}
+
+// Releases the ordnance and leaves, the planes are spent. Every attacking plane lands one hit of full damage
+// on each ship it touches, resolved against ships as the carrier's player sees them. Nothing lands if that
+// player has since moved on from the carrier.
+func (p *Plane) Drop() {
+	p.Game.Planes.Remove(p)
+
+	var player *Player = p.Carrier.Player
+	if player == nil || player.Body != p.Carrier {
+		return
+	}
+
+	for _, s := range p.Game.strikeHits(player, p) {
+		s.TakeDamage(p.Ordnance.Damage*float64(p.Attackers), p.Carrier)
+	}
+}
+
+// Takes the plane out of the sky. Whoever controls the ship that shot it is credited with it.
+func (p *Plane) ShootDown(by *Ship) {
+	p.Game.Planes.Remove(p)
+
+	if by != nil && by.Player != nil && !by.Faction.IsAlliedWith(p.Faction) {
+		by.Player.Performance.PlanesShotDown++
+	}
+}
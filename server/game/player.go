@@ -3,23 +3,118 @@ package game
 import (
 	"github.com/z46-dev/game-dev-project/server/web"
 	"github.com/z46-dev/game-dev-project/shared/definitions"
+	"github.com/z46-dev/game-dev-project/shared/movement"
+	"github.com/z46-dev/game-dev-project/shared/protocol"
 	"github.com/z46-dev/game-dev-project/util"
 )
 
-func NewPlayer(game *Game, socket *web.Socket, name string) (p *Player) {
+const MAX_QUEUED_INPUTS int = 4 // Inputs a client can get ahead by before the oldest are dropped
+
+func newPlayer(game *Game, socket *web.Socket, name string) (p *Player) {
 	p = &Player{
-		Socket: socket,
-		Body:   NewShip(game, util.RandomRadius(128), definitions.ShipColossus, NewFaction(game, name)),
-		Camera: NewCamera(2400),
+		Socket:      socket,
+		Name:        name,
+		Faction:     NewFaction(game, name),
+		Camera:      NewCamera(2400),
+		ChatLimiter: NewChatLimiter(),
+		Conduct:     NewConduct(),
+		Performance: Performance{Since: game.time},
+	}
+
+	return
+}
+
+func (p *Player) NewWriter() *protocol.Writer {
+	return protocol.NewWriter(p.Protocol)
+}
+
+// Makes the player and their body part of the simulation in one step. Call once the join has been accepted and
+// Protocol and AccountID are set. A player whose connection closed since the join was already removed, and stays
+// out. Whoever was playing on the same account leaves in that step and is returned for the caller to disconnect,
+// so the newest connection wins and two logins can never both get in.
+func (p *Player) Register(game *Game) (displaced []*Player) {
+	game.external(LogEvent{Kind: LOG_EVENT_REGISTER, Player: p.Socket.ID, Protocol: p.Protocol, Account: p.AccountID}, func() {
+		if game.joining[p.Socket.ID] != p {
+			return
+		}
+
+		delete(game.joining, p.Socket.ID)
+		if p.Body != nil {
+			game.Ships.Add(p.Body)
+		}
+
+		game.PlayersMu.Lock()
+		for _, other := range game.playersInOrder() {
+			if p.AccountID != 0 && other.AccountID == p.AccountID {
+				delete(game.Players, other.Socket.ID)
+				displaced = append(displaced, other)
+			}
+		}
+
+		game.Players[p.Socket.ID] = p
+		game.PlayersMu.Unlock()
+
+		for _, other := range displaced {
+			game.leave(other)
+		}
+
+		game.SystemMessage(p.Name + " joined the game")
+	})
+
+	return
+}
+
+// Builds the player with a body of the ship, with the squadron variants the loadout picks. Check the loadout
+// with definitions.Ship.Loadout first, one it rejects gets the default squadrons. The body enters the world
+// with Register.
+func NewPlayer(game *Game, socket *web.Socket, name string, def *definitions.Ship, loadout []uint8) (p *Player) {
+	game.external(LogEvent{Kind: LOG_EVENT_JOIN, Player: socket.ID, Name: name, ShipID: uint8(def.ID), Loadout: loadout}, func() {
+		p = newPlayer(game, socket, name)
+		p.Body = NewShip(game, util.RandomRadius(game.RNG, 128), def, p.Faction)
+		if squadrons, err := def.Loadout(loadout); err == nil {
+			p.Body.Equip(squadrons)
+		}
+
+		p.Body.Name = name
+		p.Body.Player = p
+		p.Spawned = def
+		game.joining[socket.ID] = p
+	})
+
+	return
+}
+
+// Joins without a body, straight into spectator mode
+func NewObserver(game *Game, socket *web.Socket, name string) (p *Player) {
+	game.external(LogEvent{Kind: LOG_EVENT_OBSERVE, Player: socket.ID, Name: name}, func() {
+		p = newPlayer(game, socket, name)
+		p.Spectate(game)
+		game.joining[socket.ID] = p
+	})
+
+	return
+}
+
+// Detaches the body (if any) and switches the player into spectator mode
+func (p *Player) Spectate(game *Game) {
+	if p.Body != nil {
+		p.Camera.Position = p.Body.Position.Copy()
+		p.Body = nil
 	}
 
-	p.Body.Name = name
-	game.Ships.Add(p.Body)
+	if p.Spectator == nil {
+		p.Spectator = NewSpectator(p)
+		p.Spectator.Cycle(game, 1)
+	}
+}
 
-	game.PlayersMu.Lock()
-	game.Players[socket.ID] = p
-	game.PlayersMu.Unlock()
+// Spectators ignore fog of war unless the room says otherwise
+func (p *Player) CanSee(game *Game, s *Ship) (visible bool) {
+	if p.Spectator != nil && !game.Settings.SpectatorFogOfWar {
+		return true
+	}
 
+	visible = p.Faction.CanSee(s)
 	return
 }
 
@@ -35,3 +130,51 @@ func (p *Player) GetInputFlags() (flags uint8) {
 	p.InputMu.RUnlock()
 	return
 }
+
+// Points the body where an input says, right away
+func (p *Player) ApplyInput(input *protocol.Input) {
+	if p.Body == nil {
+		return
+	}
+
+	p.Body.Control.Goal = movement.Goal(input.Flags)
+	p.ViewTick = int(input.ViewTick)
+	p.Firing = input.Flags&protocol.BITFLAG_INPUT_LMB != 0
+
+	if input.Flags&protocol.BITFLAG_MOUSE_MOVE != 0 {
+		p.Body.Control.PrimaryTarget = util.Vector(float64(input.MouseX), float64(input.MouseY))
+	}
+}
+
+// Holds a sequenced input until the next tick, so the server steps the body exactly once per input like the
+// client predicting it does
+func (p *Player) QueueInput(input protocol.Input) {
+	p.InputMu.Lock()
+	defer p.InputMu.Unlock()
+
+	if len(p.Inputs) >= MAX_QUEUED_INPUTS {
+		p.Inputs = append(p.Inputs[:0], p.Inputs[1:]...)
+	}
+
+	p.Inputs = append(p.Inputs, input)
+}
+
+// Applies the next queued input, once per tick. With nothing queued the body keeps its last goal.
+func (p *Player) ConsumeInput() {
+	if p.Body == nil || p.Protocol < protocol.PROTOCOL_PREDICTION_VERSION {
+		return
+	}
+
+	p.InputMu.Lock()
+	if len(p.Inputs) == 0 {
+		p.InputMu.Unlock()
+		return
+	}
+
+	var input protocol.Input = p.Inputs[0]
+	p.Inputs = append(p.Inputs[:0], p.Inputs[1:]...)
+	p.InputMu.Unlock()
+
+	p.ApplyInput(&input)
+	p.InputAck = input.Sequence
+}
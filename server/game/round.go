@@ -0,0 +1,75 @@
+package game
+
+// Ends the round: the faction holding the most objectives wins, everyone is paid out and the objectives go back
+// to neutral for the next one
+func (g *Game) endRound() {
+	var held map[*Faction]int = make(map[*Faction]int)
+	for _, o := range g.Objectives {
+		if o.Owner != nil {
+			held[o.Owner]++
+		}
+	}
+
+	var (
+		winner *Faction
+		best   int
+	)
+
+	for faction, count := range held {
+		switch {
+		case count > best:
+			winner, best = faction, count
+		case count == best:
+			winner = nil
+		}
+	}
+
+	if winner != nil {
+		g.SystemMessage(winner.Name + " won the round")
+	} else {
+		g.SystemMessage("The round ended in a draw")
+	}
+
+	// Players who left during the round are in the summary with what they had when they left
+	var summary *MatchSummary = &MatchSummary{Started: g.roundStart, Ended: g.time, Players: g.departed}
+	g.departed = nil
+
+	g.PlayersMu.RLock()
+	for _, player := range g.playersInOrder() {
+		player.Performance.Won = player.Faction.IsAlliedWith(winner)
+		if player.Spawned != nil {
+			summary.Players = append(summary.Players, player.summary())
+		}
+
+		g.settle(player)
+	}
+	g.PlayersMu.RUnlock()
+
+	if g.OnMatchEnd != nil && len(summary.Players) > 0 {
+		g.OnMatchEnd(summary)
+	}
+
+	for _, o := range g.Objectives {
+		o.Owner, o.Capturer, o.Progress = nil, nil, 0
+	}
+
+	g.roundStart = g.time
+}
+
+func (p *Player) summary() (line PlayerSummary) {
+	line = PlayerSummary{Name: p.Name, AccountID: p.AccountID, Performance: p.Performance}
+	if p.Spawned != nil {
+		line.Ship = p.Spawned.ID
+	}
+
+	return
+}
+
+// Hands what the player earned to OnSettle and starts them from zero
+func (g *Game) settle(p *Player) {
+	if g.OnSettle != nil {
+		g.OnSettle(p, p.Performance)
+	}
+
+	p.Performance = Performance{Since: g.time}
+}
@@ -2,6 +2,7 @@ package game
 
 import (
 	"github.com/z46-dev/game-dev-project/shared/definitions"
+	"github.com/z46-dev/game-dev-project/shared/movement"
 	"github.com/z46-dev/game-dev-project/util"
 )
 
@@ -42,10 +43,46 @@ func NewShip(g *Game, position *util.Vector2D, def *definitions.Ship, f *Faction
 	s.Health = NewHealth(s.Cfg.HullHealth, true)
 	s.Polygon = util.NewPolygon(s.Cfg.HullPath, s.Position, s.Size/2, s.Rotation)
 	s.Control = NewControl(g, s)
+	s.SpottedBy = make(map[uint64]bool)
+	s.History = &TransformHistory{}
+
+	s.Equip(s.Cfg.Squadrons)
+
+	for _, consumable := range s.Cfg.Consumables {
+		s.Consumables = append(s.Consumables, NewConsumableSlot(consumable))
+	}
 
 	return
 }
 
+// Damage from another ship. Whoever controls the attacker is credited with it, and with the kill if it sinks the
+// target.
+func (s *Ship) TakeDamage(amount float64, attacker *Ship) {
+	if !s.Health.IsAlive() {
+		return
+	}
+
+	var before float64 = s.Health.Health
+	s.Health.Damage(amount)
+
+	if attacker == nil || attacker.Player == nil || attacker.Faction.IsAlliedWith(s.Faction) {
+		return
+	}
+
+	attacker.Player.Performance.Damage += before - s.Health.Health
+	if !s.Health.IsAlive() {
+		attacker.Player.Performance.Kills++
+	}
+}
+
+// Fills the hangar with the squadrons, one per slot of the definition, replacing what was there
+func (s *Ship) Equip(squadrons []*definitions.Squadron) {
+	s.Hangar = make([]*HangarSquadron, 0, len(squadrons))
+	for _, squadron := range squadrons {
+		s.Hangar = append(s.Hangar, NewHangarSquadron(squadron))
+	}
+}
+
 func (s *Ship) GetAABB() (aabb *util.AABB) {
 	aabb = s.Polygon.AABB
 	return
@@ -58,9 +95,16 @@ func (s *Ship) Insert() {
 }
 
 func (s *Ship) Update() {
+	for _, h := range s.Hangar {
+		h.Update()
+	}
+
+	for _, c := range s.Consumables {
+		c.Update(s)
+	}
+
 	s.Control.Update()
-	s.Position.Add(s.Velocity)
-	s.Velocity.Scale(s.Friction)
+	movement.Integrate(s.Position, s.Velocity, s.Friction)
 	s.Insert()
 }
 
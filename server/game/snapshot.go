@@ -0,0 +1,339 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/z46-dev/game-dev-project/shared/definitions"
+	"github.com/z46-dev/game-dev-project/util"
+)
+
+const SNAPSHOT_VERSION int = 1 // Bump when Snapshot changes in a way older files cannot be read as
+
+// Captures the world between two ticks
+func (g *Game) TakeSnapshot() (s *Snapshot) {
+	g.TickMu.Lock()
+	defer g.TickMu.Unlock()
+
+	s = &Snapshot{
+		Version:       SNAPSHOT_VERSION,
+		Saved:         time.Now(),
+		Seed:          g.Seed,
+		Time:          g.time,
+		RoundStart:    g.roundStart,
+		NextID:        g.nextID,
+		NextFactionID: g.nextFactionID,
+	}
+
+	s.RNG, _ = g.rngState.MarshalBinary()
+
+	var (
+		bodies     map[uint64]bool = make(map[uint64]bool)
+		referenced map[uint64]bool = make(map[uint64]bool)
+		reference                  = func(f *Faction) (id uint64) {
+			if f != nil {
+				id = f.ID
+				referenced[id] = true
+			}
+
+			return
+		}
+	)
+
+	g.PlayersMu.RLock()
+	for _, p := range g.Players {
+		if p.Body != nil {
+			bodies[p.Body.ID] = true
+		}
+	}
+	g.PlayersMu.RUnlock()
+
+	// Pending, so ships added since the last tick (everything, right after Init) are not missed
+	for _, ship := range g.Ships.Pending() {
+		if bodies[ship.ID] || !ship.Health.IsAlive() {
+			continue
+		}
+
+		var saved ShipSnapshot = ShipSnapshot{
+			ID:       ship.ID,
+			Name:     ship.Name,
+			Ship:     ship.Cfg.ID,
+			Faction:  reference(ship.Faction),
+			Position: ship.Position.Copy(),
+			Velocity: ship.Velocity.Copy(),
+			Rotation: ship.Rotation,
+			Health:   ship.Health.Health,
+			Goal:     ship.Control.Goal.Copy(),
+		}
+
+		if ship.Control.PrimaryTarget != nil {
+			saved.Target = ship.Control.PrimaryTarget.Copy()
+		}
+
+		for _, h := range ship.Hangar {
+			saved.Hangar = append(saved.Hangar, HangarSnapshot{Planes: h.Planes, LaunchTimer: h.LaunchTimer, RegenTimer: h.RegenTimer})
+		}
+
+		for _, c := range ship.Consumables {
+			saved.Consumables = append(saved.Consumables, ConsumableSnapshot{Charges: c.Charges, Cooldown: c.Cooldown, Active: c.Active})
+		}
+
+		s.Ships = append(s.Ships, saved)
+	}
+
+	for _, island := range g.Islands.Pending() {
+		s.Islands = append(s.Islands, IslandSnapshot{
+			ID:       island.ID,
+			Position: island.Position.Copy(),
+			Size:     island.Size,
+			Rotation: island.Rotation,
+			Path:     island.Polygon.Reference,
+		})
+	}
+
+	for _, o := range g.Objectives {
+		s.Objectives = append(s.Objectives, ObjectiveSnapshot{
+			ID:       o.ID,
+			Name:     o.Name,
+			Position: o.Position.Copy(),
+			Radius:   o.Radius,
+			Owner:    reference(o.Owner),
+			Capturer: reference(o.Capturer),
+			Progress: o.Progress,
+		})
+	}
+
+	// Only factions something still belongs to, the ones players brought along are made again when they rejoin
+	g.FactionsMu.RLock()
+	for _, id := range slices.Sorted(maps.Keys(referenced)) {
+		var f *Faction = g.Factions[id]
+		var saved FactionSnapshot = FactionSnapshot{ID: f.ID, Name: f.Name, Color: f.Color}
+		for _, ally := range slices.Sorted(maps.Keys(f.Allies)) {
+			if referenced[ally] {
+				saved.Allies = append(saved.Allies, ally)
+			}
+		}
+
+		s.Factions = append(s.Factions, saved)
+	}
+	g.FactionsMu.RUnlock()
+
+	return
+}
+
+// Builds the world a snapshot describes. Call on a new game instead of Init, with Settings already filled in.
+func (g *Game) Restore(s *Snapshot) (err error) {
+	if s.Version != SNAPSHOT_VERSION {
+		return fmt.Errorf("snapshot v%d is not supported, expected v%d", s.Version, SNAPSHOT_VERSION)
+	}
+
+	g.SetSeed(s.Seed)
+	if len(s.RNG) > 0 {
+		if err = g.rngState.UnmarshalBinary(s.RNG); err != nil {
+			return fmt.Errorf("snapshot RNG state: %w", err)
+		}
+	}
+
+	g.time, g.roundStart = s.Time, s.RoundStart
+	g.nextID, g.nextFactionID = s.NextID, s.NextFactionID
+
+	// Hand written fixtures may leave the counters and object IDs out, new IDs must not collide with any in the file
+	for _, f := range s.Factions {
+		if f.ID == 0 {
+			return fmt.Errorf("faction %q has no ID", f.Name)
+		}
+
+		g.nextFactionID = max(g.nextFactionID, f.ID)
+	}
+
+	for _, i := range s.Islands {
+		g.nextID = max(g.nextID, i.ID)
+	}
+
+	for _, o := range s.Objectives {
+		g.nextID = max(g.nextID, o.ID)
+	}
+
+	for _, ship := range s.Ships {
+		g.nextID = max(g.nextID, ship.ID)
+	}
+
+	var (
+		top     uint64          = g.nextID
+		seen    map[uint64]bool = make(map[uint64]bool)
+		claimID                 = func(id *uint64, saved uint64) error {
+			if saved != 0 {
+				*id = saved
+			}
+
+			if seen[*id] {
+				return fmt.Errorf("ID %d is used twice", *id)
+			}
+
+			seen[*id] = true
+			top = max(top, *id)
+			return nil
+		}
+		faction = func(id uint64) (f *Faction, err error) {
+			if f = g.Factions[id]; f == nil && id != 0 {
+				err = fmt.Errorf("unknown faction %d", id)
+			}
+
+			return
+		}
+	)
+
+	// Objects given an ID still took a fresh one when made, which is handed back here
+	defer func() {
+		g.nextID = top
+	}()
+
+	for _, saved := range s.Factions {
+		if g.Factions[saved.ID] != nil {
+			return fmt.Errorf("faction ID %d is used twice", saved.ID)
+		}
+
+		addFaction(g, saved.ID, saved.Name, saved.Color)
+	}
+
+	for _, saved := range s.Factions {
+		for _, ally := range saved.Allies {
+			if g.Factions[ally] == nil {
+				return fmt.Errorf("faction %d is allied with unknown faction %d", saved.ID, ally)
+			}
+
+			g.Factions[saved.ID].AllyWith(g.Factions[ally])
+		}
+	}
+
+	for _, saved := range s.Islands {
+		if saved.Position == nil || len(saved.Path) < 3 {
+			return fmt.Errorf("island %d needs a position and at least 3 points", saved.ID)
+		}
+
+		var island *Island = NewIsland(g, saved.Position.Copy(), saved.Size, saved.Rotation, saved.Path)
+		if err = claimID(&island.ID, saved.ID); err != nil {
+			return
+		}
+
+		g.Islands.Add(island)
+	}
+
+	for _, saved := range s.Objectives {
+		if saved.Position == nil {
+			return fmt.Errorf("objective %q needs a position", saved.Name)
+		}
+
+		var o *Objective = NewObjective(g, saved.Name, saved.Position.Copy(), saved.Radius)
+		if err = claimID(&o.ID, saved.ID); err != nil {
+			return
+		}
+
+		o.Progress = saved.Progress
+		if o.Owner, err = faction(saved.Owner); err == nil {
+			o.Capturer, err = faction(saved.Capturer)
+		}
+
+		if err != nil {
+			return fmt.Errorf("objective %q: %w", saved.Name, err)
+		}
+	}
+
+	for _, saved := range s.Ships {
+		def, found := definitions.GetByKey(definitions.ShipConfigs, saved.Ship)
+		if !found {
+			return fmt.Errorf("ship %q: unknown ship %d", saved.Name, saved.Ship)
+		}
+
+		var f *Faction
+		if f, err = faction(saved.Faction); err == nil && f == nil {
+			err = errors.New("no faction")
+		}
+
+		if err != nil {
+			return fmt.Errorf("ship %q: %w", saved.Name, err)
+		}
+
+		if saved.Position == nil {
+			saved.Position = util.Vector(0, 0)
+		}
+
+		var ship *Ship = NewShip(g, saved.Position.Copy(), def, f)
+		if err = claimID(&ship.ID, saved.ID); err != nil {
+			return
+		}
+
+		if saved.Name != "" {
+			ship.Name = saved.Name
+		}
+
+		if saved.Velocity != nil {
+			ship.Velocity = saved.Velocity.Copy()
+		}
+
+		if saved.Goal != nil {
+			ship.Control.Goal = saved.Goal.Copy()
+		}
+
+		if saved.Target != nil {
+			ship.Control.PrimaryTarget = saved.Target.Copy()
+		}
+
+		// Zero health would sink it on the first tick, fixtures that leave it out get a healthy ship
+		ship.Rotation = saved.Rotation
+		if saved.Health > 0 {
+			ship.Health.Health = min(saved.Health, ship.Health.MaxHealth)
+		}
+
+		// The definition may have changed since, slots it no longer has are dropped and new ones start full
+		for i, h := range saved.Hangar[:min(len(saved.Hangar), len(ship.Hangar))] {
+			ship.Hangar[i].Planes, ship.Hangar[i].LaunchTimer, ship.Hangar[i].RegenTimer = h.Planes, h.LaunchTimer, h.RegenTimer
+		}
+
+		for i, c := range saved.Consumables[:min(len(saved.Consumables), len(ship.Consumables))] {
+			ship.Consumables[i].Charges, ship.Consumables[i].Cooldown, ship.Consumables[i].Active = c.Charges, c.Cooldown, c.Active
+		}
+
+		g.Ships.Add(ship)
+	}
+
+	return
+}
+
+// Writes next to the file and renames over it, so a crash mid-save leaves the last good snapshot in place
+func SaveSnapshot(path string, s *Snapshot) (err error) {
+	var data []byte
+	if data, err = json.MarshalIndent(s, "", "\t"); err != nil {
+		return
+	}
+
+	var temporary string = path + ".tmp"
+	if err = os.WriteFile(temporary, data, 0o644); err != nil {
+		return
+	}
+
+	if err = os.Rename(temporary, path); err != nil {
+		os.Remove(temporary)
+	}
+
+	return
+}
+
+func LoadSnapshot(path string) (s *Snapshot, err error) {
+	var data []byte
+	if data, err = os.ReadFile(path); err != nil {
+		return
+	}
+
+	s = &Snapshot{}
+	if err = json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return
+}
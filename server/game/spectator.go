@@ -0,0 +1,121 @@
+package game
+
+import (
+	"sort"
+
+	"github.com/z46-dev/game-dev-project/shared/protocol"
+	"github.com/z46-dev/game-dev-project/util"
+)
+
+const (
+	SPECTATOR_MIN_FOV        float64 = 800
+	SPECTATOR_FREE_CAM_SPEED float64 = 1.0 / 90 // Fraction of the FOV travelled per tick
+)
+
+func NewSpectator(p *Player) (s *Spectator) {
+	s = &Spectator{
+		Player: p,
+		Goal:   util.Vector(0, 0),
+	}
+
+	return
+}
+
+// Ships the spectator is allowed to follow, ordered by ID so cycling is stable
+func (s *Spectator) candidates(g *Game) (ships []*Ship) {
+	g.Ships.ForEach(func(ship *Ship) {
+		if !s.AllFactions && !s.Player.Faction.IsAlliedWith(ship.Faction) {
+			return
+		}
+
+		if !s.Player.CanSee(g, ship) {
+			return
+		}
+
+		ships = append(ships, ship)
+	})
+
+	sort.Slice(ships, func(i, j int) bool {
+		return ships[i].ID < ships[j].ID
+	})
+
+	return
+}
+
+func (s *Spectator) Cycle(g *Game, direction int) {
+	var ships []*Ship = s.candidates(g)
+	if len(ships) == 0 {
+		s.TargetID = 0
+		return
+	}
+
+	var index int = sort.Search(len(ships), func(i int) bool {
+		return ships[i].ID >= s.TargetID
+	})
+
+	if index < len(ships) && ships[index].ID == s.TargetID {
+		index += direction
+	} else if direction < 0 {
+		index--
+	}
+
+	index = ((index % len(ships)) + len(ships)) % len(ships)
+	s.TargetID = ships[index].ID
+	s.FreeCam = false
+}
+
+func (s *Spectator) HandleAction(g *Game, action *protocol.SpectateAction) {
+	switch action.Action {
+	case protocol.SPECTATE_ACTION_NEXT:
+		s.Cycle(g, 1)
+	case protocol.SPECTATE_ACTION_PREVIOUS:
+		s.Cycle(g, -1)
+	case protocol.SPECTATE_ACTION_TOGGLE_FREE_CAM:
+		s.FreeCam = !s.FreeCam
+	case protocol.SPECTATE_ACTION_TOGGLE_ALL_FACTIONS:
+		s.AllFactions = !s.AllFactions
+	case protocol.SPECTATE_ACTION_SET_FOV:
+		s.Player.Camera.FOV = max(SPECTATOR_MIN_FOV, min(g.Settings.SpectatorMaxFOV, float64(action.FOV)))
+	}
+}
+
+// Moves the camera onto the followed ship, or flies it freely
+func (s *Spectator) Update(g *Game, c *Camera) {
+	if !s.FreeCam {
+		var target *Ship
+		if s.TargetID != 0 {
+			target = g.Ships.Get(s.TargetID)
+		}
+
+		if target == nil || !s.Player.CanSee(g, target) || (!s.AllFactions && !s.Player.Faction.IsAlliedWith(target.Faction)) {
+			s.Cycle(g, 1)
+			if s.TargetID != 0 {
+				target = g.Ships.Get(s.TargetID)
+			}
+		}
+
+		if target != nil {
+			c.Position = target.Position.Copy()
+			return
+		}
+
+		// Nothing left to follow, fly freely this tick and look again on the next one
+	}
+
+	if s.Goal.SquaredMagnitude() > 0 {
+		c.Position = c.Position.Copy().Add(s.Goal.Copy().Normalize().Scale(c.FOV * SPECTATOR_FREE_CAM_SPEED))
+	}
+}
+
+// Free cam is reported while flying freely for lack of a ship to follow too, so the client steers the camera
+func (s *Spectator) Flags() (flags uint8) {
+	if s.FreeCam || s.TargetID == 0 {
+		flags |= protocol.BITFLAG_SPECTATE_FREE_CAM
+	}
+
+	if s.AllFactions {
+		flags |= protocol.BITFLAG_SPECTATE_ALL_FACTIONS
+	}
+
+	return
+}
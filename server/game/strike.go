@@ -0,0 +1,72 @@
+package game
+
+import (
+	"github.com/z46-dev/game-dev-project/shared/definitions"
+	"github.com/z46-dev/game-dev-project/util"
+)
+
+const STRIKE_MIN_COOLDOWN int = TPS // Squadrons without a strike cooldown of their own still wait this long between attacks
+
+// Rockets, bombs and mines land on an elliptical reticle released from its distance, torpedoes and skip bombs
+// run along a line released halfway along it so the run crosses the aim point. False for squadrons that carry
+// nothing that does damage.
+func ordnanceOf(ammo *definitions.PlaneAmmo) (o Ordnance, armed bool) {
+	switch {
+	case ammo.Rocket != nil:
+		o = Ordnance{Damage: ammo.Rocket.FullDamage, Radius: max(ammo.Rocket.Width, ammo.Rocket.Height) / 2, Release: ammo.Rocket.Distance}
+	case ammo.Bomb != nil:
+		o = Ordnance{Damage: ammo.Bomb.FullDamage, Radius: max(ammo.Bomb.Width, ammo.Bomb.Height) / 2, Release: ammo.Bomb.Distance}
+	case ammo.Mine != nil:
+		o = Ordnance{Damage: ammo.Mine.FullDamage, Radius: max(ammo.Mine.Width, ammo.Mine.Height) / 2, Release: ammo.Mine.Distance}
+	case ammo.Torpedo != nil:
+		o = Ordnance{Damage: ammo.Torpedo.FullDamage, Length: ammo.Torpedo.Length, Release: ammo.Torpedo.Length / 2}
+	case ammo.SkipBomb != nil:
+		o = Ordnance{Damage: ammo.SkipBomb.FullDamage, Length: ammo.SkipBomb.Length, Release: ammo.SkipBomb.Length / 2}
+	}
+
+	armed = o.Damage > 0 && (o.Radius > 0 || o.Length > 0)
+	return
+}
+
+// While the strike button is held, launches the first ready squadron at the body's aim point. The attacking
+// planes leave the hangar, which regenerates them over time, and fly there as a Plane.
+func (p *Player) Strike(g *Game) {
+	if !p.Firing || p.Body == nil || p.Body.Control.PrimaryTarget == nil {
+		return
+	}
+
+	for _, h := range p.Body.Hangar {
+		var attackers int = max(1, h.Cfg.AttacksWith)
+		ordnance, armed := ordnanceOf(&h.Cfg.Ammo)
+		if !armed || h.LaunchTimer > 0 || h.Planes < attackers {
+			continue
+		}
+
+		h.Planes -= attackers
+		h.LaunchTimer = max(STRIKE_MIN_COOLDOWN, h.Cfg.CooldownBetweenStrikes)
+		p.LastFireTick = g.time
+
+		g.Planes.Add(NewPlane(g, p.Body, h.Cfg, p.Body.Control.PrimaryTarget.Copy(), attackers, ordnance))
+		return
+	}
+}
+
+// Ships a flight's ordnance hits where it is released. Running ordnance starts under the planes and heads
+// the way they are flying.
+func (g *Game) strikeHits(player *Player, plane *Plane) (hits []*Ship) {
+	var o Ordnance = plane.Ordnance
+	if o.Length <= 0 {
+		return g.AimedArea(player, plane.Target, o.Radius)
+	}
+
+	var heading *util.Vector2D = plane.Target.Copy().Subtract(plane.Position)
+	if heading.SquaredMagnitude() == 0 {
+		heading = util.VectorFromAngle(plane.Rotation, 1)
+	}
+
+	if hit := g.AimedShot(player, plane.Position, plane.Position.Copy().Add(heading.Normalize().Scale(o.Length))); hit != nil {
+		hits = append(hits, hit)
+	}
+
+	return
+}
@@ -1,8 +1,13 @@
 package game
 
 import (
+	"bufio"
+	"encoding/json"
 	"image/color"
+	"math/rand/v2"
+	"os"
 	"sync"
+	"time"
 
 	"github.com/z46-dev/game-dev-project/server/web"
 	"github.com/z46-dev/game-dev-project/shared/definitions"
@@ -18,12 +23,35 @@ type (
 		Collide()
 	}
 
+	// Per-room rules, filled in from the server configuration
+	RoomSettings struct {
+		SpectatorFogOfWar bool
+		SpectatorMaxFOV   float64
+		MapExtent         float64
+		MatchLength       int     // In ticks, 0 for an open-ended match
+		PositionPrecision float64 // World units per step of a quantized position offset
+		MaxRewindTicks    int     // How far back aimed shots may be checked, capped by TRANSFORM_HISTORY_SIZE
+		ViewByteBudget    int     // Soft cap on a view update, lower priority ships wait for a later tick
+	}
+
 	Game struct {
+		Settings                       RoomSettings
+		Seed                           uint64                                   // Everything random in the simulation is drawn from RNG, seeded with this
+		RNG                            *rand.Rand                               // Only touched by the tick and by changes holding TickMu
+		rngState                       *rand.PCG                                // Behind RNG, part of the state hash
+		TickMu                         sync.Mutex                               // Held for a whole tick and by every change from outside, so none lands in the middle of one
+		InputLog                       *InputLog                                // Records outside changes and state hashes, nil when not logging
+		OnSettle                       func(p *Player, performance Performance) // Pays a player out, at the end of each round and when they leave. Called under TickMu.
+		OnMatchEnd                     func(summary *MatchSummary)              // Called under TickMu at the end of each round, or when a player leaves an open-ended match
+		departed                       []PlayerSummary                          // Lines of players who left during the current round
 		time                           int
+		roundStart                     int // Tick the current round started on
 		nextID, nextFactionID          uint64
 		Factions                       map[uint64]*Faction
 		FactionsMu                     sync.RWMutex
 		Ships                          *util.SafeStorage[*Ship]
+		Islands                        *util.SafeStorage[*Island]
+		Objectives                     []*Objective
 		Planes                         *util.SafeStorage[*Plane]
 		spatialHash                    *util.SpatialHash[CollidableObject]
 		ShipCache                      map[uint64]*ShipCache
@@ -31,12 +59,20 @@ type (
 		ShipCacheMu, ProjectileCacheMu sync.RWMutex
 		Players                        map[int]*Player
 		PlayersMu                      sync.RWMutex
+		joining                        map[int]*Player // Created by a join but not registered yet, guarded by TickMu
+		Mutes                          *MuteList
+		chatFilters                    []ChatFilter
+		chatMu                         sync.RWMutex
 	}
 
 	Camera struct {
 		Position        *util.Vector2D
+		Center          *util.Vector2D // Position written in the last view header, quantized offsets are relative to it
 		FOV             float64
 		ShipsSeen       map[uint64]bool
+		ShipsSent       map[uint64]*SentShip
+		Priority        map[uint64]float64 // Accumulated interest of ships in view, reset when one is sent
+		IslandsSeen     map[uint64]bool
 		ProjectilesSeen map[uint64]bool
 	}
 
@@ -45,16 +81,195 @@ type (
 		Name             string
 		Color            color.RGBA
 		ShipsSpatialHash *util.SpatialHash[*Ship]
+		Allies           map[uint64]bool // Factions that share spotting information with this one
 	}
 
 	Player struct {
-		Socket       *web.Socket
-		Body         *Ship
-		Camera       *Camera
-		InputFlags   uint8
-		LastFireTick int
-		InputMu      sync.RWMutex
-		Faction      *Faction
+		Name          string
+		AccountID     uint64 // 0 for guests
+		Socket        *web.Socket
+		Body          *Ship
+		Camera        *Camera
+		InputFlags    uint8
+		LastFireTick  int
+		Firing        bool // Holding the strike button, the body attacks whenever a squadron is ready
+		InputMu       sync.RWMutex
+		Faction       *Faction
+		Spectator     *Spectator // Set while the player has no body
+		SentMapStatic bool       // Whether the static part of the map has been sent
+		Score         int
+		Performance   Performance       // Earned since the player was last paid out
+		Spawned       *definitions.Ship // What the player joined as, nil for observers
+		lastGUI       []byte            // Last GUI update sent, so unchanged ones can be skipped
+		ChatLimiter   *ChatLimiter
+		Protocol      uint16           // Wire format negotiated during the join
+		Inputs        []protocol.Input // Sequenced inputs waiting for a tick, guarded by InputMu
+		InputAck      uint32           // Sequence of the last input applied to the body
+		ViewTick      int              // Server tick the client was drawing at its last input, 0 if it never said
+		LastSequence  uint32           // Highest input sequence received, later ones must be higher
+		Conduct       *Conduct
+	}
+
+	// What a player achieved since they were last paid out
+	Performance struct {
+		Since          int     // Tick counting started on, the join or the end of the last round
+		Damage         float64 // Dealt to enemy ships
+		Kills, Deaths  int64
+		PlanesShotDown int64 // Credited by Plane.ShootDown, 0 until something can shoot planes down
+		Captures       int64
+		Score          int64
+		TimeAlive      int // Ticks spent with a body afloat
+		Won            bool
+	}
+
+	// One player's line in a match summary
+	PlayerSummary struct {
+		Name      string
+		AccountID uint64 // 0 for guests
+		Ship      definitions.ShipID
+		Performance
+	}
+
+	// What a round came to, or with open-ended matches a single player's session
+	MatchSummary struct {
+		Started, Ended int // Ticks
+		Players        []PlayerSummary
+	}
+
+	// First line of an input log, what a replay needs to rebuild the room before the first tick
+	InputLogHeader struct {
+		Seed            uint64
+		Settings        RoomSettings
+		TPS             int
+		DefinitionsHash uint32
+		Started         time.Time
+		Snapshot        *Snapshot `json:",omitempty"` // World the match was restored from, nil if Init built it from the seed
+	}
+
+	// A change from outside the simulation, made between two ticks
+	LogEvent struct {
+		Tick     int                      // Ticks completed when it was made
+		Kind     uint8                    // LOG_EVENT_*
+		Player   int                      // Socket ID
+		Name     string                   `json:",omitempty"`
+		ShipID   uint8                    `json:",omitempty"`
+		Loadout  []uint8                  `json:",omitempty"`
+		Protocol uint16                   `json:",omitempty"`
+		Account  uint64                   `json:",omitempty"`
+		Input    *protocol.Input          `json:",omitempty"`
+		Slot     uint8                    `json:",omitempty"`
+		Spectate *protocol.SpectateAction `json:",omitempty"`
+	}
+
+	// State hash at the end of a tick
+	LogCheckpoint struct {
+		Tick int
+		Hash uint64
+	}
+
+	// One line of an input log, with exactly one field set
+	LogLine struct {
+		Header     *InputLogHeader `json:",omitempty"`
+		Event      *LogEvent       `json:",omitempty"`
+		Checkpoint *LogCheckpoint  `json:",omitempty"`
+	}
+
+	// JSON lines, written under TickMu so they are in the order the simulation saw them
+	InputLog struct {
+		file    *os.File
+		writer  *bufio.Writer
+		encoder *json.Encoder
+		err     error
+		OnError func(err error) // Called once if writing fails, logging stops after that
+	}
+
+	InputLogReader struct {
+		Header  InputLogHeader
+		file    *os.File
+		scanner *bufio.Scanner
+	}
+
+	// Re-runs a logged match tick for tick
+	Replay struct {
+		Game    *Game
+		players map[int]*Player // By socket ID, including ones that joined but were not registered yet
+	}
+
+	// The world between two ticks, as saved to disk. Players are left out, they join a restored world like any
+	// other, and so are the bodies they were controlling.
+	Snapshot struct {
+		Version               int
+		Saved                 time.Time
+		Seed                  uint64
+		RNG                   []byte // Marshalled PCG state, empty to start the generator fresh from Seed
+		Time                  int    // Ticks completed
+		RoundStart            int    `json:",omitempty"` // Tick the current round started on
+		NextID, NextFactionID uint64
+		Factions              []FactionSnapshot
+		Islands               []IslandSnapshot
+		Objectives            []ObjectiveSnapshot
+		Ships                 []ShipSnapshot
+	}
+
+	FactionSnapshot struct {
+		ID     uint64
+		Name   string
+		Color  color.RGBA
+		Allies []uint64 `json:",omitempty"`
+	}
+
+	IslandSnapshot struct {
+		ID             uint64 // 0 picks a new one
+		Position       *util.Vector2D
+		Size, Rotation float64
+		Path           []*util.Vector2D // Coastline normalized to -1 to 1
+	}
+
+	ObjectiveSnapshot struct {
+		ID              uint64 // 0 picks a new one
+		Name            string
+		Position        *util.Vector2D
+		Radius          float64
+		Owner, Capturer uint64 `json:",omitempty"` // Faction IDs, 0 for none
+		Progress        float64
+	}
+
+	ShipSnapshot struct {
+		ID                 uint64 // 0 picks a new one
+		Name               string
+		Ship               definitions.ShipID
+		Faction            uint64
+		Position, Velocity *util.Vector2D
+		Rotation, Health   float64
+		Goal               *util.Vector2D
+		Target             *util.Vector2D       `json:",omitempty"`
+		Hangar             []HangarSnapshot     // In the order of the definition's squadrons
+		Consumables        []ConsumableSnapshot // In the order of the definition's consumables
+	}
+
+	HangarSnapshot struct {
+		Planes, LaunchTimer, RegenTimer int
+	}
+
+	ConsumableSnapshot struct {
+		Charges, Cooldown, Active int
+	}
+
+	// A capture zone. Ships of a single alliance inside the radius slowly take it over.
+	Objective struct {
+		ID              uint64
+		Name            string
+		Position        *util.Vector2D
+		Radius          float64
+		Owner, Capturer *Faction
+		Progress        float64 // 0 to 1, how far the capturer is toward taking the objective
+	}
+
+	Spectator struct {
+		Player               *Player
+		FreeCam, AllFactions bool
+		TargetID             uint64
+		Goal                 *util.Vector2D
 	}
 
 	// All game object should embed this either directly or through another embedded struct
@@ -83,21 +298,76 @@ type (
 
 	Ship struct {
 		PolygonalCollisionPlugin
-		Name    string
-		Cfg     *definitions.Ship
-		Health  *HealthComponent
-		Control *Control
+		Name        string
+		Cfg         *definitions.Ship
+		Health      *HealthComponent
+		Control     *Control
+		SpottedBy   map[uint64]bool // IDs of the factions that spotted this ship during the current tick
+		Hangar      []*HangarSquadron
+		Consumables []*ConsumableSlot
+		History     *TransformHistory
+		Player      *Player // Who controls the ship, nil for NPCs
+	}
+
+	// Where a ship's polygon was at the end of a tick
+	Transform struct {
+		Tick                 int
+		X, Y, Size, Rotation float64
+	}
+
+	// Ring buffer of a ship's recent transforms, indexed by tick, for rewinding hit checks
+	TransformHistory struct {
+		entries [TRANSFORM_HISTORY_SIZE]Transform
+	}
+
+	// Live state of one of the ship's squadrons
+	HangarSquadron struct {
+		Cfg                     *definitions.Squadron
+		Planes                  int // Planes left in reserve
+		LaunchTimer, RegenTimer int // Ticks until the squadron can launch / the next plane is regenerated
+	}
+
+	// What a squadron attacks with, flattened from whichever ammo it carries
+	Ordnance struct {
+		Damage  float64 // Per attacking plane
+		Radius  float64 // Area ordnance hits every ship it touches
+		Length  float64 // Running ordnance hits the first ship this far along the line of attack
+		Release float64 // Distance from the aim point the planes drop at
+	}
+
+	ConsumableSlot struct {
+		Cfg                       *definitions.Consumable
+		Charges, Cooldown, Active int
+	}
+
+	// Static terrain that blocks movement and line of sight
+	Island struct {
+		PolygonalCollisionPlugin
 	}
 
+	// A flight of planes launched from a carrier's hangar, on its way to drop its ordnance
 	Plane struct {
 		CircularCollisionPlugin
+		Cfg       *definitions.Squadron
+		Carrier   *Ship          // Launched the flight, credited with whatever it hits
+		Target    *util.Vector2D // Aim point when it was launched
+		Attackers int            // Planes in the flight, each lands one hit
+		Ordnance  Ordnance
+		Flight    int // Ticks in the air
+	}
+
+	// What a quantizing client was last told about a ship, so changes smaller than a step can be held back
+	SentShip struct {
+		X, Y         float64 // As the client decoded them
+		Angle        uint16
+		Size, Health float64
 	}
 
 	// Caches (Each renderable type should have a cache, using inheretence where possible)
 
 	GenericObjectCache struct {
-		AsOf                                int // Corresponds with Game.time
-		New, Old                            *protocol.Writer
+		AsOf                                int                                             // Corresponds with Game.time
+		New, Old                            [protocol.PROTOCOL_VERSION + 1]*protocol.Writer // Indexed by protocol version
 		ID                                  uint64
 		X, Y, Size, Rotation                float64
 		PosChanged, SizeChanged, RotChanged bool
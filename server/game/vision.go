@@ -0,0 +1,74 @@
+package game
+
+import (
+	"github.com/z46-dev/game-dev-project/util"
+)
+
+// Checks whether any island lies between the two points
+func (g *Game) HasLineOfSight(from, to *util.Vector2D) (clear bool) {
+	for _, something := range g.spatialHash.Retrieve(&util.AABB{
+		X1: min(from.X, to.X),
+		Y1: min(from.Y, to.Y),
+		X2: max(from.X, to.X),
+		Y2: max(from.Y, to.Y),
+	}) {
+		if island, ok := something.(*Island); ok && island.Polygon.SegmentIntersects(from, to) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Spotting phase. Must run after every ship has been inserted into the spatial hash for this tick.
+func (g *Game) updateVision() {
+	g.Ships.ForEach(func(s *Ship) {
+		clear(s.SpottedBy)
+	})
+
+	g.Ships.ForEach(func(spotter *Ship) {
+		if spotter.Faction == nil || spotter.Cfg.DetectionRange <= 0 {
+			return
+		}
+
+		for _, something := range g.spatialHash.RetrieveAround(spotter.Position.X, spotter.Position.Y, spotter.Cfg.DetectionRange) {
+			var target *Ship
+			var ok bool
+			if target, ok = something.(*Ship); !ok || target == spotter || target.SpottedBy[spotter.Faction.ID] {
+				continue
+			}
+
+			if spotter.Faction.IsAlliedWith(target.Faction) {
+				continue
+			}
+
+			var spotRange float64 = min(spotter.Cfg.DetectionRange, target.Cfg.Concealment)
+			if util.SquaredDistance(spotter.Position, target.Position) > spotRange*spotRange {
+				continue
+			}
+
+			if g.HasLineOfSight(spotter.Position, target.Position) {
+				target.SpottedBy[spotter.Faction.ID] = true
+			}
+		}
+	})
+}
+
+// Whether the faction (or any of its allies) currently has vision of the ship
+func (f *Faction) CanSee(s *Ship) (visible bool) {
+	if f == nil {
+		return false
+	}
+
+	if f.IsAlliedWith(s.Faction) {
+		return true
+	}
+
+	for id := range s.SpottedBy {
+		if id == f.ID || f.Allies[id] {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/z46-dev/game-dev-project/server/config"
+	"github.com/z46-dev/game-dev-project/server/game"
+	"github.com/z46-dev/game-dev-project/server/web"
+	"github.com/z46-dev/game-dev-project/shared/definitions"
+	"github.com/z46-dev/game-dev-project/shared/protocol"
+	"github.com/z46-dev/golog"
+)
+
+var definitionsHash uint32 = definitions.Hash()
+
+// Sends the reason (PACKET_CLIENTBOUND_JOIN_REJECT or PACKET_CLIENTBOUND_KICK) and closes the socket
+func disconnect(socket *web.Socket, version uint16, packetType uint8, reason string) {
+	var writer *protocol.Writer = protocol.NewWriter(version)
+	writer.SetU8(packetType)
+	(&protocol.Disconnect{Reason: reason}).Write(writer)
+	socket.Write(writer.GetBytes())
+	socket.Close()
+}
+
+// Version is the one the client asked for, or PROTOCOL_MIN_VERSION when it is unknown or unsupported
+func rejectJoin(socket *web.Socket, version uint16, reason string) {
+	socket.Logger.Warningf("Join rejected: %s", reason)
+	disconnect(socket, version, protocol.PACKET_CLIENTBOUND_JOIN_REJECT, reason)
+}
+
+// Anything that fails to decode is treated as a broken or hostile client
+func kickMalformed(player *game.Player, packetType uint8, err error) {
+	player.Conduct.Record(&game.Violation{Kind: game.VIOLATION_MALFORMED, Detail: fmt.Sprintf("packet %d: %v", packetType, err)})
+	player.Socket.Logger.Warningf("Kicked for malformed packet %d: %v", packetType, err)
+	disconnect(player.Socket, player.Protocol, protocol.PACKET_CLIENTBOUND_KICK, "Malformed packet")
+}
+
+// The offending packet has already been dropped, repeat offenders are warned and then kicked
+func enforce(player *game.Player, violation *game.Violation) {
+	switch player.Conduct.Record(violation) {
+	case game.CONDUCT_WARN:
+		player.Socket.Logger.Warningf("Warned for %v (%s)", violation, player.Conduct.Summary())
+		player.SendChat(protocol.CHAT_CHANNEL_SYSTEM, game.CHAT_SYSTEM_NAME, "Your client is sending invalid or too many inputs, keep it up and you will be kicked")
+	case game.CONDUCT_KICK:
+		player.Socket.Logger.Warningf("Kicked for repeated violations (%s)", player.Conduct.Summary())
+		disconnect(player.Socket, player.Protocol, protocol.PACKET_CLIENTBOUND_KICK, "Too many invalid inputs")
+	}
+}
+
+// Validates a PACKET_SERVERBOUND_JOIN and spawns the player. Returns nil if the join was rejected.
+func handleJoin(socket *web.Socket, ip string, reader *protocol.Reader) (player *game.Player) {
+	var version uint16 = reader.GetU16()
+	if reader.Err() != nil {
+		rejectJoin(socket, protocol.PROTOCOL_MIN_VERSION, "Malformed join packet")
+		return
+	}
+
+	if version < protocol.PROTOCOL_MIN_VERSION || version > protocol.PROTOCOL_VERSION {
+		rejectJoin(socket, protocol.PROTOCOL_MIN_VERSION, fmt.Sprintf("Protocol version mismatch: server supports v%d to v%d, client is v%d. Please update your client.", protocol.PROTOCOL_MIN_VERSION, protocol.PROTOCOL_VERSION, version))
+		return
+	}
+
+	// Everything from here on is in the client's version
+	reader.Version = version
+
+	var join protocol.Join
+	if err := join.Read(reader); err != nil {
+		rejectJoin(socket, version, "Malformed join packet")
+		return
+	}
+
+	var (
+		shipID uint8  = join.ShipID
+		room   string = join.Room
+	)
+
+	if room != config.Config.Game.Room {
+		rejectJoin(socket, version, fmt.Sprintf("Unknown room %q", room))
+		return
+	}
+
+	username, accountID, reason := identify(&join)
+	if reason != "" {
+		rejectJoin(socket, version, reason)
+		return
+	}
+
+	if shipID == protocol.JOIN_SHIP_SPECTATE {
+		player = game.NewObserver(g, socket, username)
+	} else {
+		def, found := definitions.GetByKey(definitions.ShipConfigs, definitions.ShipID(shipID))
+		if !found {
+			rejectJoin(socket, version, fmt.Sprintf("Unknown ship %d", shipID))
+			return
+		}
+
+		if reason := checkShip(def, accountID); reason != "" {
+			rejectJoin(socket, version, reason)
+			return
+		}
+
+		if _, err := def.Loadout(join.Loadout); err != nil {
+			rejectJoin(socket, version, fmt.Sprintf("Invalid loadout: %v", err))
+			return
+		}
+
+		player = game.NewPlayer(g, socket, username, def, join.Loadout)
+	}
+
+	socket.Logger.Prefix(fmt.Sprintf("[#%d:%s:%s]", socket.ID, ip, username), golog.BoldGreen)
+	if accountID != 0 {
+		socket.Logger.Infof("Joined as account #%d", accountID)
+	} else {
+		socket.Logger.Info("Joined as a guest")
+	}
+
+	player.AccountID = accountID
+	player.Protocol = version
+
+	var writer *protocol.Writer = player.NewWriter()
+	var accept *protocol.JoinAccept = &protocol.JoinAccept{
+		PlayerID:          uint32(socket.ID),
+		TickRate:          uint8(game.TPS),
+		DefinitionsHash:   definitionsHash,
+		PositionPrecision: float32(g.Settings.PositionPrecision),
+	}
+
+	if player.Body != nil {
+		accept.EntityID = player.Body.ID
+	}
+
+	writer.SetU8(protocol.PACKET_CLIENTBOUND_JOIN_ACCEPT)
+	accept.Write(writer)
+	socket.Write(writer.GetBytes())
+
+	// Only start simulating (and sending view updates) once the client knows it was accepted
+	kickDisplaced(player.Register(g))
+	return
+}
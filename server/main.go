@@ -1,22 +1,30 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
 
+	"github.com/z46-dev/game-dev-project/database/api"
 	"github.com/z46-dev/game-dev-project/server/config"
 	"github.com/z46-dev/game-dev-project/server/game"
 	"github.com/z46-dev/game-dev-project/server/web"
 	"github.com/z46-dev/game-dev-project/shared/protocol"
-	"github.com/z46-dev/game-dev-project/util"
 	"github.com/z46-dev/golog"
 )
 
 var log *golog.Logger = golog.New().Prefix("[MAIN]", golog.BoldBlue).Timestamp()
 
+const JOIN_TIMEOUT time.Duration = 10 * time.Second
+
 func discoverTLSKeys(dir string) (certPath, keyPath string, found bool) {
 	type Candidate struct {
 		cert string
@@ -75,7 +83,22 @@ func fileExists(p string) bool {
 	return !info.IsDir()
 }
 
-var g *game.Game = game.NewGame()
+var (
+	g  *game.Game  = game.NewGame()
+	db *api.Client // nil when no database service is configured
+)
+
+func remoteIP(request *http.Request) (ip string) {
+	ip = request.RemoteAddr
+
+	if strings.Contains(ip, "]:") {
+		ip = strings.Split(strings.Split(ip, "]:")[0], "[")[1]
+	} else if strings.Contains(ip, ":") {
+		ip = strings.Split(ip, ":")[0]
+	}
+
+	return
+}
 
 func handleWebSocket(writer http.ResponseWriter, request *http.Request) {
 	// Set CORS headers
@@ -92,13 +115,7 @@ func handleWebSocket(writer http.ResponseWriter, request *http.Request) {
 		return
 	}
 
-	var ip string = request.RemoteAddr
-
-	if strings.Contains(ip, "]:") {
-		ip = strings.Split(strings.Split(ip, "]:")[0], "[")[1]
-	} else if strings.Contains(ip, ":") {
-		ip = strings.Split(ip, ":")[0]
-	}
+	var ip string = remoteIP(request)
 
 	socket.Logger = golog.New().Prefix(fmt.Sprintf("[#%d:%s]", socket.ID, ip), golog.BoldGreen).Timestamp()
 	socket.Logger.Info("Connection established")
@@ -108,23 +125,17 @@ func handleWebSocket(writer http.ResponseWriter, request *http.Request) {
 		game.RemovePlayer(g, socket.ID)
 	}
 
-	var username string = request.URL.Query().Get("name")
-
-	if username == "" || len(username) < 3 {
-		socket.Logger.Warning("Invalid username")
-		var writer *protocol.Writer = new(protocol.Writer)
-		writer.SetU8(protocol.PACKET_CLIENTBOUND_KICK)
-		writer.SetStringUTF8("Invalid Username")
-		socket.Write(writer.GetBytes())
-		socket.Close()
-		return
-	}
-
-	socket.Logger.Prefix(fmt.Sprintf("[#%d:%s:%s]", socket.ID, ip, username), golog.BoldGreen)
-
-	socket.Logger.Info("Joined")
+	var (
+		player *game.Player
+		joined atomic.Bool
+	)
 
-	var player *game.Player = game.NewPlayer(g, socket, username)
+	// Clients that never send a join packet are dropped
+	time.AfterFunc(JOIN_TIMEOUT, func() {
+		if !joined.Load() && socket.Open {
+			rejectJoin(socket, protocol.PROTOCOL_MIN_VERSION, "Join timed out")
+		}
+	})
 
 	go socket.InitiateUpdateLoop(func(message []byte) {
 		if len(message) < 1 {
@@ -133,43 +144,118 @@ func handleWebSocket(writer http.ResponseWriter, request *http.Request) {
 
 		var reader *protocol.Reader = protocol.NewReader(message)
 		var packetType uint8 = reader.GetU8()
+		if player != nil {
+			reader.Version = player.Protocol
+		}
 
-		switch packetType {
-		case protocol.PACKET_SERVERBOUND_INPUT:
-			if len(message) < 2 {
-				return
+		// Nothing but the handshake is accepted until the player has joined
+		if player == nil {
+			if packetType == protocol.PACKET_SERVERBOUND_JOIN {
+				if player = handleJoin(socket, ip, reader); player != nil {
+					joined.Store(true)
+				}
 			}
 
-			var inputFlags uint8 = reader.GetU8()
-			player.Body.Control.Goal = util.Vector(0, 0)
+			return
+		}
 
-			if inputFlags&protocol.BITFLAG_INPUT_UP != 0 {
-				player.Body.Control.Goal.Y -= 1
-			}
+		if violation := player.Conduct.Allow(packetType); violation != nil {
+			enforce(player, violation)
+			return
+		}
 
-			if inputFlags&protocol.BITFLAG_INPUT_DOWN != 0 {
-				player.Body.Control.Goal.Y += 1
+		if err := handlePacket(player, packetType, reader); err != nil {
+			if violation, ok := err.(*game.Violation); ok {
+				enforce(player, violation)
+				return
 			}
 
-			if inputFlags&protocol.BITFLAG_INPUT_LEFT != 0 {
-				player.Body.Control.Goal.X -= 1
-			}
+			kickMalformed(player, packetType, err)
+		}
+	})
+}
 
-			if inputFlags&protocol.BITFLAG_INPUT_RIGHT != 0 {
-				player.Body.Control.Goal.X += 1
-			}
+// Decodes and applies one packet from a joined player, returning an error if it is malformed or a
+// *game.Violation if it breaks the rules
+func handlePacket(player *game.Player, packetType uint8, reader *protocol.Reader) (err error) {
+	switch packetType {
+	case protocol.PACKET_SERVERBOUND_INPUT:
+		var input protocol.Input
+		if err = input.Read(reader); err != nil {
+			return
+		}
 
-			if inputFlags&protocol.BITFLAG_MOUSE_MOVE != 0 {
-				if len(message) < 10 {
-					return
-				}
+		if violation := g.SubmitInput(player, input); violation != nil {
+			return violation
+		}
+	case protocol.PACKET_SERVERBOUND_CONSUMABLE:
+		var activate protocol.ConsumableActivate
+		if err = activate.Read(reader); err != nil {
+			return
+		}
 
-				var mouseX float32 = reader.GetF32()
-				var mouseY float32 = reader.GetF32()
-				player.Body.Control.PrimaryTarget = util.Vector(float64(mouseX), float64(mouseY))
-			}
+		g.ActivateConsumable(player, activate.Slot)
+	case protocol.PACKET_SERVERBOUND_CHAT:
+		var chat protocol.ChatSend
+		if err = chat.Read(reader); err != nil {
+			return
 		}
-	})
+
+		g.HandleChat(player, chat.Channel, chat.Target, chat.Message)
+	case protocol.PACKET_SERVERBOUND_SPECTATE:
+		var action protocol.SpectateAction
+		if err = action.Read(reader); err != nil {
+			return
+		}
+
+		g.Spectate(player, action)
+	default:
+		err = fmt.Errorf("unknown packet type %d", packetType)
+	}
+
+	return
+}
+
+func saveWorld(path string) {
+	var (
+		started  time.Time      = time.Now()
+		snapshot *game.Snapshot = g.TakeSnapshot()
+	)
+
+	if err := game.SaveSnapshot(path, snapshot); err != nil {
+		log.Errorf("Could not save snapshot: %v", err)
+		return
+	}
+
+	log.Infof("Saved %d ships to %s at tick %d in %s", len(snapshot.Ships), path, snapshot.Time, time.Since(started).Round(time.Millisecond))
+}
+
+func autosave(path string, interval time.Duration) {
+	var ticker *time.Ticker = time.NewTicker(interval)
+	for range ticker.C {
+		saveWorld(path)
+	}
+}
+
+// Saves the world and closes the input log before exiting on Ctrl+C or SIGTERM
+func shutdownOnSignal() {
+	var signals chan os.Signal = make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	log.Infof("Shutting down (%s)", <-signals)
+
+	if config.Config.Game.Snapshot != "" {
+		saveWorld(config.Config.Game.Snapshot)
+	}
+
+	// Held until exit, so no tick writes to the log after it is closed
+	g.TickMu.Lock()
+	if g.InputLog != nil {
+		if err := g.InputLog.Close(); err != nil {
+			log.Errorf("Could not close input log: %v", err)
+		}
+	}
+
+	os.Exit(0)
 }
 
 func main() {
@@ -180,9 +266,87 @@ func main() {
 	}
 
 	http.HandleFunc("/ws", handleWebSocket)
+	http.HandleFunc("POST /register", handleRegister)
+	http.HandleFunc("POST /login", handleLogin)
+	http.HandleFunc("POST /progress", handleProgress)
+	http.HandleFunc("POST /unlock", handleUnlock)
+	http.HandleFunc("GET /leaderboard", handleLeaderboard)
+
+	g.Settings.SpectatorFogOfWar = config.Config.Game.SpectatorFogOfWar
+	g.Settings.SpectatorMaxFOV = config.Config.Game.SpectatorMaxFOV
+	g.Settings.MapExtent = config.Config.Game.MapExtent
+	g.Settings.MatchLength = config.Config.Game.MatchLength * game.TPS
+	g.Settings.PositionPrecision = config.Config.Network.PositionPrecision
+	g.Settings.MaxRewindTicks = min(config.Config.Network.MaxRewind*game.TPS/1000, game.TRANSFORM_HISTORY_SIZE-1)
+	g.Settings.ViewByteBudget = config.Config.Network.ViewBudget
+
+	// Offsets are clamped to 16 bits, so a coarse enough precision has to cover the widest view
+	if reach := config.Config.Network.PositionPrecision * math.MaxInt16; reach < g.Settings.SpectatorMaxFOV/2 {
+		log.Warningf("position_precision %g only reaches %g units from the camera, spectators can see %g", config.Config.Network.PositionPrecision, reach, g.Settings.SpectatorMaxFOV/2)
+	}
+
+	if len(config.Config.Chat.BannedWords) > 0 {
+		g.AddChatFilter(game.NewWordListFilter(config.Config.Chat.BannedWords))
+	}
+
+	if url := config.Config.Database.URL; url != "" {
+		db = api.NewClient(url, config.Config.Database.APIKey)
+		if health, err := db.Health(); err != nil {
+			log.Warningf("Database service at %s is not answering yet: %v", url, err)
+		} else {
+			log.Infof("Connected to the database service at %s (schema v%d)", url, health.Schema)
+		}
+
+		g.OnSettle = payOut
+		g.OnMatchEnd = recordMatch
+	}
+
+	if config.Config.Game.Seed != 0 {
+		g.SetSeed(config.Config.Game.Seed)
+	}
+
+	var restored *game.Snapshot
+	if path := config.Config.Game.Snapshot; path != "" {
+		if restored, err = game.LoadSnapshot(path); err == nil {
+			err = g.Restore(restored)
+		}
+
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			log.Infof("No snapshot at %s yet, building a new world", path)
+		case err != nil:
+			log.Panicf("Could not restore snapshot: %v", err)
+			return
+		default:
+			log.Infof("Restored %d ships from %s, saved %s at tick %d", len(restored.Ships), path, restored.Saved.Format(time.DateTime), restored.Time)
+		}
+	}
+
+	if restored == nil {
+		g.Init()
+	}
+
+	log.Infof("Simulation seed %d", g.Seed)
+
+	if path := config.Config.Game.InputLog; path != "" {
+		if g.InputLog, err = game.CreateInputLog(path, g, restored); err != nil {
+			log.Panicf("Could not create input log: %v", err)
+			return
+		}
+
+		g.InputLog.OnError = func(err error) {
+			log.Errorf("Input log stopped: %v", err)
+		}
+
+		log.Infof("Logging inputs to %s", path)
+	}
+
+	if config.Config.Game.Snapshot != "" && config.Config.Game.AutosaveInterval > 0 {
+		go autosave(config.Config.Game.Snapshot, time.Duration(config.Config.Game.AutosaveInterval)*time.Second)
+	}
 
-	g.Init()
-	go g.BeginUpdateLoop(30)
+	go shutdownOnSignal()
+	go g.BeginUpdateLoop(game.TPS)
 
 	if config.Config.WebServer.TLSDir != "" {
 		log.Info("Starting HTTPS server...")
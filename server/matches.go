@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/z46-dev/game-dev-project/database/api"
+	"github.com/z46-dev/game-dev-project/server/config"
+	"github.com/z46-dev/game-dev-project/server/game"
+)
+
+const (
+	LEADERBOARD_CACHE_TIME time.Duration = 30 * time.Second // Boards are served from memory this long before asking the database again
+	LEADERBOARD_SIZE       int           = 10
+	LEADERBOARD_CACHE_SIZE int           = 64 // Custom windows make for any number of boards, the cache starts over past this many
+)
+
+type cachedLeaderboard struct {
+	board   api.Leaderboard
+	fetched time.Time
+}
+
+var (
+	leaderboards   map[string]*cachedLeaderboard = make(map[string]*cachedLeaderboard)
+	leaderboardsMu sync.Mutex
+)
+
+func ticksToDuration(ticks int) time.Duration {
+	return time.Duration(ticks) * time.Second / time.Duration(game.TPS)
+}
+
+// Stores a round or session summary. Runs under TickMu, so the request goes out in the background.
+func recordMatch(summary *game.MatchSummary) {
+	var ended time.Time = time.Now().UTC()
+	var record *api.MatchRecord = &api.MatchRecord{
+		Room:    config.Config.Game.Room,
+		Started: ended.Add(-ticksToDuration(summary.Ended - summary.Started)),
+		Ended:   ended,
+	}
+
+	// Someone who left and came back during a round has two lines, an account is only in a record once
+	var byAccount map[uint64]int = make(map[uint64]int)
+	for _, line := range summary.Players {
+		var player api.MatchPlayer = api.MatchPlayer{
+			AccountID:      line.AccountID,
+			Name:           line.Name,
+			Ship:           line.Ship,
+			Won:            line.Won,
+			Kills:          line.Kills,
+			Deaths:         line.Deaths,
+			PlanesShotDown: line.PlanesShotDown,
+			Captures:       line.Captures,
+			Damage:         line.Damage,
+			TimeAlive:      int64(ticksToDuration(line.TimeAlive) / time.Second),
+			Score:          line.Score,
+		}
+
+		if index, seen := byAccount[line.AccountID]; seen && line.AccountID != 0 {
+			var merged *api.MatchPlayer = &record.Players[index]
+			merged.Ship, merged.Won = player.Ship, merged.Won || player.Won
+			merged.Kills += player.Kills
+			merged.Deaths += player.Deaths
+			merged.PlanesShotDown += player.PlanesShotDown
+			merged.Captures += player.Captures
+			merged.Damage += player.Damage
+			merged.TimeAlive += player.TimeAlive
+			merged.Score += player.Score
+			continue
+		}
+
+		byAccount[line.AccountID] = len(record.Players)
+		record.Players = append(record.Players, player)
+	}
+
+	go func() {
+		recorded, err := db.RecordMatch(record)
+		if err != nil {
+			log.Errorf("Could not record a match of %d players: %v", len(record.Players), err)
+			return
+		}
+
+		log.Infof("Recorded match #%d with %d players", recorded.ID, len(record.Players))
+	}()
+}
+
+// Public, so anyone can look at the boards without an account. GET /leaderboard?metric=kills&window=weekly
+func handleLeaderboard(writer http.ResponseWriter, request *http.Request) {
+	writer.Header().Set("Access-Control-Allow-Origin", "*")
+	if db == nil {
+		writeAccountError(writer, http.StatusNotFound, "This server does not have accounts")
+		return
+	}
+
+	var metric, window string = request.URL.Query().Get("metric"), request.URL.Query().Get("window")
+	if metric == "" {
+		metric = "score"
+	}
+
+	var key string = metric + "/" + window
+	leaderboardsMu.Lock()
+	cached := leaderboards[key]
+	leaderboardsMu.Unlock()
+
+	if cached == nil || time.Since(cached.fetched) > LEADERBOARD_CACHE_TIME {
+		board, err := db.Leaderboard(metric, window, LEADERBOARD_SIZE)
+		switch {
+		case errors.Is(err, api.ErrInvalid):
+			writeAccountError(writer, http.StatusBadRequest, err.Error())
+			return
+		case err != nil:
+			log.Errorf("Could not load the %s leaderboard: %v", key, err)
+			writeAccountError(writer, http.StatusServiceUnavailable, "Leaderboards are unavailable right now")
+			return
+		}
+
+		cached = &cachedLeaderboard{board: board, fetched: time.Now()}
+		leaderboardsMu.Lock()
+		if len(leaderboards) >= LEADERBOARD_CACHE_SIZE {
+			clear(leaderboards)
+		}
+
+		leaderboards[key] = cached
+		leaderboardsMu.Unlock()
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	writer.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(LEADERBOARD_CACHE_TIME/time.Second)))
+	json.NewEncoder(writer).Encode(&cached.board)
+}
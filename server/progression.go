@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/z46-dev/game-dev-project/database/api"
+	"github.com/z46-dev/game-dev-project/server/game"
+	"github.com/z46-dev/game-dev-project/shared/definitions"
+	"github.com/z46-dev/game-dev-project/shared/protocol"
+)
+
+type (
+	// Body of POST /progress
+	ProgressRequest struct {
+		Token string
+	}
+
+	// Body of POST /unlock
+	UnlockRequest struct {
+		Token string
+		Ship  definitions.ShipID
+	}
+
+	// Answer to both, where the account stands afterwards
+	ProgressResponse struct {
+		XP, Credits int64
+		Ships       []definitions.ShipID // Unlocked ones, starter ships are always available
+	}
+)
+
+// Pays what a player earned into their account. Runs under TickMu, so the request goes out in the background.
+func payOut(p *game.Player, performance game.Performance) {
+	if db == nil || p.AccountID == 0 {
+		return
+	}
+
+	xp, credits := definitions.Earnings(performance.Damage, performance.Kills, performance.Captures, performance.Won)
+	if xp == 0 && credits == 0 {
+		return
+	}
+
+	p.SendChat(protocol.CHAT_CHANNEL_SYSTEM, game.CHAT_SYSTEM_NAME, fmt.Sprintf("You earned %d XP and %d credits", xp, credits))
+
+	var accountID uint64 = p.AccountID
+	go func() {
+		if _, err := db.Award(accountID, api.Wallet{XP: xp, Credits: credits}); err != nil {
+			p.Socket.Logger.Errorf("Could not pay out %d XP and %d credits: %v", xp, credits, err)
+		}
+	}()
+}
+
+// Starter ships are open to everyone, the rest only to accounts that unlocked them. Without a database there
+// is nothing to unlock, so every ship is open. A non-empty reason means the join is rejected.
+func checkShip(def *definitions.Ship, accountID uint64) (reason string) {
+	if db == nil || def.IsStarter() {
+		return
+	}
+
+	if accountID == 0 {
+		return fmt.Sprintf("%s has to be unlocked, log in to play it", def.Name)
+	}
+
+	progress, err := db.Progress(accountID)
+	if err != nil {
+		log.Errorf("Could not check the ships of account #%d: %v", accountID, err)
+		return "Accounts are unavailable right now, try again later"
+	}
+
+	if !definitions.CanSelect(def, progress.Ships) {
+		return fmt.Sprintf("You have not unlocked %s yet", def.Name)
+	}
+
+	return
+}
+
+// The account behind a session token. Returns false once it has answered.
+func sessionAccount(writer http.ResponseWriter, token string) (account api.Account, ok bool) {
+	account, err := db.ValidateSession(token)
+	switch {
+	case errors.Is(err, api.ErrUnauthorized):
+		writeAccountError(writer, http.StatusUnauthorized, "Your session has expired, please log in again")
+		return
+	case err != nil:
+		log.Errorf("Session check failed: %v", err)
+		writeAccountError(writer, http.StatusServiceUnavailable, "Accounts are unavailable right now")
+		return
+	}
+
+	return account, true
+}
+
+func writeProgress(writer http.ResponseWriter, progress *api.Progress) {
+	writer.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(writer).Encode(&ProgressResponse{XP: progress.XP, Credits: progress.Credits, Ships: progress.Ships})
+}
+
+func handleProgress(writer http.ResponseWriter, request *http.Request) {
+	var body ProgressRequest
+	if !readAccountRequest(writer, request, &body) {
+		return
+	}
+
+	account, ok := sessionAccount(writer, body.Token)
+	if !ok {
+		return
+	}
+
+	progress, err := db.Progress(account.ID)
+	if err != nil {
+		log.Errorf("Could not load the progress of account #%d: %v", account.ID, err)
+		writeAccountError(writer, http.StatusServiceUnavailable, "Accounts are unavailable right now")
+		return
+	}
+
+	writeProgress(writer, &progress)
+}
+
+// Checked against the tech tree here for a clear answer, the database service checks again as it spends
+func handleUnlock(writer http.ResponseWriter, request *http.Request) {
+	var body UnlockRequest
+	if !readAccountRequest(writer, request, &body) {
+		return
+	}
+
+	def, found := definitions.GetByKey(definitions.ShipConfigs, body.Ship)
+	if !found {
+		writeAccountError(writer, http.StatusBadRequest, fmt.Sprintf("Unknown ship %d", body.Ship))
+		return
+	}
+
+	account, ok := sessionAccount(writer, body.Token)
+	if !ok {
+		return
+	}
+
+	progress, err := db.Progress(account.ID)
+	if err == nil {
+		if reason := definitions.CanResearch(def, progress.Ships, progress.XP, progress.Credits); reason != nil {
+			writeAccountError(writer, http.StatusBadRequest, reason.Error())
+			return
+		}
+
+		progress, err = db.Unlock(account.ID, def.ID)
+	}
+
+	switch {
+	case errors.Is(err, api.ErrInvalid):
+		// Spent elsewhere between the check and the unlock
+		writeAccountError(writer, http.StatusConflict, "Your balance changed, try again")
+		return
+	case err != nil:
+		log.Errorf("Could not unlock %s for account #%d: %v", def.Name, account.ID, err)
+		writeAccountError(writer, http.StatusServiceUnavailable, "Accounts are unavailable right now")
+		return
+	}
+
+	log.Infof("Account #%d unlocked %s", account.ID, def.Name)
+	writeProgress(writer, &progress)
+}
@@ -71,6 +71,16 @@ func NewSocket(connection *websocket.Conn) (socket *Socket) {
 	return
 }
 
+// A socket with no connection behind it, for players replayed from a log. Writes are dropped.
+func NewDetachedSocket(id int) (socket *Socket) {
+	socket = &Socket{
+		ID:     id,
+		Logger: golog.New().Prefix(fmt.Sprintf("[#%d:replay]", id), golog.BoldGreen),
+	}
+
+	return
+}
+
 func (socket *Socket) ReadAndValidate() (message []byte, err error) {
 	var messageType int
 	if messageType, message, err = socket.connection.ReadMessage(); err == nil && messageType != websocket.BinaryMessage {
@@ -123,6 +133,10 @@ func (socket *Socket) CreateLoop(callback func(), t time.Duration) {
 }
 
 func (socket *Socket) Write(message []byte) error {
+	if socket.connection == nil {
+		return nil
+	}
+
 	socket.mu.Lock()
 	defer socket.mu.Unlock()
 	return socket.connection.WriteMessage(websocket.BinaryMessage, message)
@@ -131,6 +145,10 @@ func (socket *Socket) Write(message []byte) error {
 func (socket *Socket) Close() error {
 	socket.handleClose()
 
+	if socket.connection == nil {
+		return nil
+	}
+
 	return socket.connection.Close()
 }
 
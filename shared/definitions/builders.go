@@ -26,6 +26,20 @@ func (s *Ship) SetHullProps(health, speed, turnSpeed float64) (sh *Ship) {
 	return
 }
 
+func (s *Ship) SetVisionProps(detectionRange, concealment float64) (sh *Ship) {
+	s.DetectionRange = detectionRange
+	s.Concealment = concealment
+	sh = s
+	return
+}
+
+// Places the ship in the tech tree, behind the listed ships
+func (s *Ship) SetResearchProps(xp, credits int64, requires ...ShipID) (sh *Ship) {
+	s.Research = Research{Requires: requires, XP: xp, Credits: credits}
+	sh = s
+	return
+}
+
 // Plane Builder
 
 func NewPlane(id PlaneID, name string, size float64, assetName string) (p *Plane) {
@@ -48,6 +62,27 @@ func (p *Plane) SetFlightProps(health, speed, turnSpeed float64) (pl *Plane) {
 	return
 }
 
+// Consumable Builder
+
+func NewConsumable(id ConsumableID, name string) (c *Consumable) {
+	c = &Consumable{
+		ID:   id,
+		Name: name,
+	}
+
+	ConsumableConfigs[id] = c
+	return
+}
+
+func (c *Consumable) SetUsageProps(charges, cooldown, duration int, strength float64) (co *Consumable) {
+	c.Charges = charges
+	c.Cooldown = cooldown
+	c.Duration = duration
+	c.Strength = strength
+	co = c
+	return
+}
+
 // Ammo Builders
 
 func NewDamageSource(fullDamage, penetration, fireChance float64) DamageSource {
@@ -200,7 +235,27 @@ func (s *Squadron) SetHangarProps(hangarSize, planePrepTime, planeLaunchTime, pl
 	return s
 }
 
+// A copy of the squadron carrying different ammo, for offering another payload on the same planes
+func (s *Squadron) Variant(ammo *PlaneAmmo) *Squadron {
+	var variant Squadron = *s
+	return variant.WithAmmo(ammo)
+}
+
+// Adds a slot, with the squadron as its default
 func (s *Ship) AddSquadron(squadron *Squadron) *Ship {
 	s.Squadrons = append(s.Squadrons, squadron)
+	s.Variants = append(s.Variants, []*Squadron{squadron})
+	return s
+}
+
+// Offers another squadron in the last slot added
+func (s *Ship) AddSquadronVariant(squadron *Squadron) *Ship {
+	var slot int = len(s.Variants) - 1
+	s.Variants[slot] = append(s.Variants[slot], squadron)
+	return s
+}
+
+func (s *Ship) AddConsumable(consumable *Consumable) *Ship {
+	s.Consumables = append(s.Consumables, consumable)
 	return s
 }
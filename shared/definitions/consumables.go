@@ -0,0 +1,7 @@
+package definitions
+
+var ConsumableRepairParty *Consumable = NewConsumable(CONSUMABLE_REPAIR_PARTY, "Repair Party").
+	SetUsageProps(2, 30*80, 30*20, 0.14)
+
+var ConsumableEngineBoost *Consumable = NewConsumable(CONSUMABLE_ENGINE_BOOST, "Engine Boost").
+	SetUsageProps(0, 30*90, 30*30, 1.2)
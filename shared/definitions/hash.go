@@ -0,0 +1,60 @@
+package definitions
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"slices"
+)
+
+func sortedKeys[U ~int | ~uint8, T any](confs map[U]*T) (keys []U) {
+	keys = make([]U, 0, len(confs))
+	for key := range confs {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+	return
+}
+
+func writeSquadron(w io.Writer, s *Squadron) {
+	fmt.Fprintf(w, "squadron:%d:%s:%d:%d:%t:%t:%d:%d:%d:%d:%d:%d:%d;", s.Plane.ID, s.Ammo.Kind(), s.Ammo.Number, s.HangarSize, s.IsRTS, s.IsTactical, s.SquadronSize, s.AttacksWith, s.CooldownBetweenStrikes, s.PlanePrepTime, s.PlaneLaunchTime, s.PlaneRecoveryTime, s.PlaneRegenerationTime)
+}
+
+// Hash fingerprints every definition so client and server can tell when their builds disagree
+func Hash() uint32 {
+	var h = fnv.New32a()
+
+	for _, id := range sortedKeys(ShipConfigs) {
+		var s *Ship = ShipConfigs[id]
+		fmt.Fprintf(h, "ship:%d:%s:%d:%s:%g:%g:%g:%g:%g:%g;", s.ID, s.Name, s.Classification, s.AssetName, s.Size, s.HullHealth, s.Speed, s.TurnSpeed, s.DetectionRange, s.Concealment)
+		for _, p := range s.HullPath {
+			fmt.Fprintf(h, "%g,%g;", p.X, p.Y)
+		}
+
+		for slot, variants := range s.Variants {
+			fmt.Fprintf(h, "slot:%d;", slot)
+			for _, squadron := range variants {
+				writeSquadron(h, squadron)
+			}
+		}
+
+		for _, c := range s.Consumables {
+			fmt.Fprintf(h, "consumable:%d;", c.ID)
+		}
+
+		fmt.Fprintf(h, "research:%v:%d:%d;", s.Research.Requires, s.Research.XP, s.Research.Credits)
+	}
+
+	for _, id := range sortedKeys(PlaneConfigs) {
+		var p *Plane = PlaneConfigs[id]
+		fmt.Fprintf(h, "plane:%d:%s:%g:%g:%g:%g;", p.ID, p.Name, p.Size, p.Health, p.Speed, p.TurnSpeed)
+	}
+
+	for _, id := range sortedKeys(ConsumableConfigs) {
+		var c *Consumable = ConsumableConfigs[id]
+		fmt.Fprintf(h, "consumable:%d:%s:%d:%d:%d:%g;", c.ID, c.Name, c.Charges, c.Cooldown, c.Duration, c.Strength)
+	}
+
+	return h.Sum32()
+}
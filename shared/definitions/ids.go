@@ -5,6 +5,8 @@ import "fmt"
 var (
 	ShipConfigs  map[ShipID]*Ship   = make(map[ShipID]*Ship)
 	PlaneConfigs map[PlaneID]*Plane = make(map[PlaneID]*Plane)
+
+	ConsumableConfigs map[ConsumableID]*Consumable = make(map[ConsumableID]*Consumable)
 )
 
 func GetByKey[T any, U comparable](confs map[U]*T, key U) (*T, bool) {
@@ -47,3 +49,8 @@ const (
 	PLANE_B6N_TENZAN
 	PLANE_D4Y3_SUISEI
 )
+
+const (
+	CONSUMABLE_REPAIR_PARTY ConsumableID = iota
+	CONSUMABLE_ENGINE_BOOST
+)
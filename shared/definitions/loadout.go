@@ -0,0 +1,49 @@
+package definitions
+
+import "fmt"
+
+// What the squadron drops, for naming a variant
+func (a *PlaneAmmo) Kind() string {
+	switch {
+	case a.Rocket != nil:
+		return "Rockets"
+	case a.Torpedo != nil:
+		return "Torpedoes"
+	case a.Bomb != nil:
+		return "Bombs"
+	case a.SkipBomb != nil:
+		return "Skip bombs"
+	case a.Mine != nil:
+		return "Mines"
+	default:
+		return "Guns"
+	}
+}
+
+// Shown when picking a variant, e.g. "Fairey Barracuda Mk V (Mines)"
+func (s *Squadron) Label() string {
+	return fmt.Sprintf("%s (%s)", s.Plane.Name, s.Ammo.Kind())
+}
+
+// The squadrons a loadout picks, one variant index per slot. Slots past the end of the loadout keep their default.
+func (s *Ship) Loadout(choice []uint8) (squadrons []*Squadron, err error) {
+	if len(choice) > len(s.Variants) {
+		return nil, fmt.Errorf("%s has %d squadron slots, the loadout picks %d", s.Name, len(s.Variants), len(choice))
+	}
+
+	squadrons = make([]*Squadron, len(s.Variants))
+	for slot, variants := range s.Variants {
+		var index int
+		if slot < len(choice) {
+			index = int(choice[slot])
+		}
+
+		if index >= len(variants) {
+			return nil, fmt.Errorf("slot %d of %s offers variants 0 to %d, the loadout picks %d", slot+1, s.Name, len(variants)-1, index)
+		}
+
+		squadrons[slot] = variants[index]
+	}
+
+	return
+}
@@ -0,0 +1,72 @@
+package definitions
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// What a match pays out, per unit of each achievement
+const (
+	XP_PER_DAMAGE       float64 = 0.01
+	XP_PER_KILL         int64   = 150
+	XP_PER_CAPTURE      int64   = 100
+	XP_PER_WIN          int64   = 300
+	CREDITS_PER_DAMAGE  float64 = 0.1
+	CREDITS_PER_KILL    int64   = 1500
+	CREDITS_PER_CAPTURE int64   = 1000
+	CREDITS_PER_WIN     int64   = 3000
+)
+
+// Experience and currency earned for a stretch of play
+func Earnings(damage float64, kills, captures int64, won bool) (xp, credits int64) {
+	xp = int64(math.Floor(damage*XP_PER_DAMAGE)) + kills*XP_PER_KILL + captures*XP_PER_CAPTURE
+	credits = int64(math.Floor(damage*CREDITS_PER_DAMAGE)) + kills*CREDITS_PER_KILL + captures*CREDITS_PER_CAPTURE
+	if won {
+		xp += XP_PER_WIN
+		credits += CREDITS_PER_WIN
+	}
+
+	return
+}
+
+// Every ship in ID order, the tech tree as a list
+func SortedShips() (ships []*Ship) {
+	for _, id := range sortedKeys(ShipConfigs) {
+		ships = append(ships, ShipConfigs[id])
+	}
+
+	return
+}
+
+func (s *Ship) IsStarter() bool {
+	return len(s.Research.Requires) == 0 && s.Research.XP == 0 && s.Research.Credits == 0
+}
+
+// Whether a player with these unlocks may spawn as the ship
+func CanSelect(s *Ship, unlocked []ShipID) bool {
+	return s.IsStarter() || slices.Contains(unlocked, s.ID)
+}
+
+// Why the ship cannot be unlocked yet, nil if it can. Unlocking one that is already unlocked is not an error.
+func CanResearch(s *Ship, unlocked []ShipID, xp, credits int64) error {
+	if CanSelect(s, unlocked) {
+		return nil
+	}
+
+	for _, id := range s.Research.Requires {
+		if required := MustGetByKey(ShipConfigs, id); !CanSelect(required, unlocked) {
+			return fmt.Errorf("%s needs %s first", s.Name, required.Name)
+		}
+	}
+
+	if xp < s.Research.XP {
+		return fmt.Errorf("%s needs %d XP, you have %d", s.Name, s.Research.XP, xp)
+	}
+
+	if credits < s.Research.Credits {
+		return fmt.Errorf("%s costs %d credits, you have %d", s.Name, s.Research.Credits, credits)
+	}
+
+	return nil
+}
@@ -14,6 +14,14 @@ var ColossusBomberSquadron *Squadron = NewSquadron(
 	NewPlaneAmmo(0).WithBomb(&PlaneAmmoBomb{}),
 ).SetStrikeProps(true, false, 9, 3, 0).SetHangarProps(22, 0, 0, 0, 0)
 
+var ColossusSkipBomberSquadron *Squadron = ColossusBomberSquadron.Variant(NewPlaneAmmo(1).WithSkipBomb(
+	NewPlaneAmmoSkipBomb(NewDamageSource(4400, 90, 0.25), NewSkipReticle(600, 90, 40, 3), 1.5),
+))
+
+var ColossusMineLayerSquadron *Squadron = ColossusBomberSquadron.Variant(NewPlaneAmmo(1).WithMine(
+	NewPlaneAmmoMine(NewDamageSource(2600, 70, 0), NewEllipticalReticle(700, 160, 60), 3*30, 90*30),
+))
+
 var ShipColossus *Ship = NewShip(SHIP_COLOSSUS, "Colossus", ShipClassificationCarrier, []*util.Vector2D{
 	util.Vector(1, -0.097),
 	util.Vector(0.716, -0.117),
@@ -59,6 +67,7 @@ var ShipColossus *Ship = NewShip(SHIP_COLOSSUS, "Colossus", ShipClassificationCa
 	util.Vector(0.996, 0.111),
 }, 212, "colossus.png").
 	SetHullProps(47600, 25, 890).
+	SetVisionProps(1800, 1250).
 	AddSquadron(
 		NewSquadron(PlaneVoughtCorsairMkIV, NewPlaneAmmo(5).WithRocket(&PlaneAmmoRocket{
 			DamageSource: DamageSource{
@@ -78,7 +87,11 @@ var ShipColossus *Ship = NewShip(SHIP_COLOSSUS, "Colossus", ShipClassificationCa
 	AddSquadron(
         NewSquadron(PlaneFaireyBarracudaMkV, NewPlaneAmmo(0).WithTorpedo(&PlaneAmmoTorpedo{})).
     SetStrikeProps(true, false, 6, 3, 0).SetHangarProps(14, 0, 0, 0, 0)).
-	AddSquadron(ColossusBomberSquadron)
+	AddSquadron(ColossusBomberSquadron).
+	AddSquadronVariant(ColossusSkipBomberSquadron).
+	AddSquadronVariant(ColossusMineLayerSquadron).
+	AddConsumable(ConsumableRepairParty).
+	AddConsumable(ConsumableEngineBoost)
 
 var ShipEnterprise *Ship = NewShip(SHIP_ENTERPRISE, "Enterprise", ShipClassificationCarrier, []*util.Vector2D{
 	util.Vector(0.997, -0.091),
@@ -102,7 +115,11 @@ var ShipEnterprise *Ship = NewShip(SHIP_ENTERPRISE, "Enterprise", ShipClassifica
 	util.Vector(0.831, 0.123),
 	util.Vector(0.997, 0.117),
 }, 251.38, "enterprise.png").
-	SetHullProps(51400, 32.5, 1070)
+	SetHullProps(51400, 32.5, 1070).
+	SetVisionProps(1750, 1300).
+	SetResearchProps(1500, 15000, SHIP_COLOSSUS).
+	AddConsumable(ConsumableRepairParty).
+	AddConsumable(ConsumableEngineBoost)
 
 var ShipChkalov *Ship = NewShip(SHIP_CHKALOV, "Chkalov", ShipClassificationCarrier, []*util.Vector2D{
 	util.Vector(0.998, -0.083),
@@ -156,7 +173,11 @@ var ShipChkalov *Ship = NewShip(SHIP_CHKALOV, "Chkalov", ShipClassificationCarri
 	util.Vector(0.794, 0.139),
 	util.Vector(0.996, 0.113),
 }, 224, "chkalov.png").
-	SetHullProps(51700, 3.3, 1040)
+	SetHullProps(51700, 3.3, 1040).
+	SetVisionProps(1700, 1200).
+	SetResearchProps(1500, 15000, SHIP_COLOSSUS).
+	AddConsumable(ConsumableRepairParty).
+	AddConsumable(ConsumableEngineBoost)
 
 var ShipParseval *Ship = NewShip(SHIP_PARSEVAL, "August Von Parseval", ShipClassificationCarrier, []*util.Vector2D{
 	util.Vector(0.998, -0.055),
@@ -212,4 +233,8 @@ var ShipParseval *Ship = NewShip(SHIP_PARSEVAL, "August Von Parseval", ShipClass
 	util.Vector(0.73, 0.141),
 	util.Vector(0.998, 0.089),
 }, 233, "parseval.png").
-	SetHullProps(50000, 31.8, 1140)
+	SetHullProps(50000, 31.8, 1140).
+	SetVisionProps(1650, 1150).
+	SetResearchProps(4000, 40000, SHIP_CHKALOV).
+	AddConsumable(ConsumableRepairParty).
+	AddConsumable(ConsumableEngineBoost)
@@ -8,6 +8,7 @@ type (
 	SquadronType       uint8 // Represents the type of squadron
 	ShipID             int   // Represents the key of a ship definition
 	PlaneID            int   // Represents the key of a plane definition
+	ConsumableID       uint8 // Represents the key of a consumable definition
 
 	Ship struct {
 		ID             ShipID             // The unique identifier for the ship
@@ -19,7 +20,28 @@ type (
 		HullHealth     float64            // The health of the ship's hull
 		Speed          float64            // The speed of the ship
 		TurnSpeed      float64            // The maximum turn speed of the ship in radians per tick
-		Squadrons      []*Squadron        // The squadrons carried by the ship
+		DetectionRange float64            // The maximum distance at which the ship can spot other ships
+		Concealment    float64            // The distance within which the ship is spotted by enemies
+		Squadrons      []*Squadron        // The squadrons carried by the ship, the default variant of each slot
+		Variants       [][]*Squadron      // Per slot, the squadrons a player may pick from. Index 0 is the default.
+		Consumables    []*Consumable      // The consumables the ship can activate
+		Research       Research           // Where the ship sits in the tech tree
+	}
+
+	// What unlocking a ship takes. A ship with no requirements and no cost is a starter, open to everyone.
+	Research struct {
+		Requires []ShipID // Ships that must all be unlocked first
+		XP       int64    // Experience spent to unlock
+		Credits  int64    // Currency spent to unlock
+	}
+
+	Consumable struct {
+		ID       ConsumableID // The unique identifier for the consumable
+		Name     string       // The name of the consumable
+		Charges  int          // The number of uses per life (0 = unlimited)
+		Cooldown int          // Cooldown in ticks after activation
+		Duration int          // Time in ticks the effect stays active
+		Strength float64      // Effect strength, meaning depends on the consumable (heal fraction, speed multiplier...)
 	}
 
 	EllipticalReticle struct {
@@ -0,0 +1,99 @@
+// Ship movement model. The server runs it authoritatively, the client runs the same code to predict its
+// own ship between view updates, so anything changed here changes both.
+package movement
+
+import (
+	"math"
+
+	"github.com/z46-dev/game-dev-project/shared/protocol"
+	"github.com/z46-dev/game-dev-project/util"
+)
+
+const (
+	SPEED_DIVISOR    float64 = 120  // Ship definitions give speed per 120 ticks of thrust
+	DEFAULT_FRICTION float64 = 0.95 // Fraction of velocity kept each tick
+)
+
+// State of a moving body, a copy the client can step without touching anything else
+type Body struct {
+	Position, Velocity *util.Vector2D
+	Rotation           float64
+}
+
+// How a body handles, per tick
+type Handling struct {
+	Acceleration, TurnSpeed, Friction float64
+}
+
+func WrapAngle(angle float64) float64 {
+	for angle > math.Pi {
+		angle -= math.Pi * 2
+	}
+
+	for angle < -math.Pi {
+		angle += math.Pi * 2
+	}
+
+	return angle
+}
+
+// Per tick acceleration of a ship with the given definition speed
+func Acceleration(speed, multiplier float64) float64 {
+	return speed / SPEED_DIVISOR * multiplier
+}
+
+// Direction the movement keys in an input point in, zero when none (or opposing ones) are held
+func Goal(flags uint8) (goal *util.Vector2D) {
+	goal = util.Vector(0, 0)
+
+	if flags&protocol.BITFLAG_INPUT_UP != 0 {
+		goal.Y -= 1
+	}
+
+	if flags&protocol.BITFLAG_INPUT_DOWN != 0 {
+		goal.Y += 1
+	}
+
+	if flags&protocol.BITFLAG_INPUT_LEFT != 0 {
+		goal.X -= 1
+	}
+
+	if flags&protocol.BITFLAG_INPUT_RIGHT != 0 {
+		goal.X += 1
+	}
+
+	return
+}
+
+// Turns toward the goal by at most turnSpeed and thrusts along the new heading. A nil or zero goal coasts.
+func Steer(rotation *float64, velocity, goal *util.Vector2D, acceleration, turnSpeed float64) {
+	if goal == nil || goal.Magnitude() == 0 {
+		return
+	}
+
+	var delta float64 = WrapAngle(goal.Direction() - *rotation)
+	*rotation += min(turnSpeed, max(-turnSpeed, delta))
+
+	velocity.X += math.Cos(*rotation) * acceleration
+	velocity.Y += math.Sin(*rotation) * acceleration
+}
+
+// Moves by the velocity, then bleeds it off
+func Integrate(position, velocity *util.Vector2D, friction float64) {
+	position.Add(velocity)
+	velocity.Scale(friction)
+}
+
+// One full tick, in the same order the server runs it
+func (b *Body) Step(goal *util.Vector2D, h Handling) {
+	Steer(&b.Rotation, b.Velocity, goal, h.Acceleration, h.TurnSpeed)
+	Integrate(b.Position, b.Velocity, h.Friction)
+}
+
+func (b *Body) Copy() *Body {
+	return &Body{
+		Position: b.Position.Copy(),
+		Velocity: b.Velocity.Copy(),
+		Rotation: b.Rotation,
+	}
+}
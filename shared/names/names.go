@@ -0,0 +1,80 @@
+// Display name rules, shared by account registration, guest joins and clients that want to check a name
+// before sending it.
+package names
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	MIN_LENGTH int = 3
+	MAX_LENGTH int = 24
+)
+
+// Names that would pass for the server or staff. Compared after lowercasing and dropping separators, so
+// "Ad_min" and "S.E.R.V.E.R" are caught too.
+var (
+	RESERVED       []string = []string{"server", "system", "npc", "npcs", "guest", "spectator"}
+	RESERVED_PARTS []string = []string{"admin", "moderator", "official"}
+)
+
+func separator(r rune) bool {
+	return r == ' ' || r == '_' || r == '-' || r == '.'
+}
+
+// Letters, digits and single separators (space, _, - and .) between them, MIN_LENGTH to MAX_LENGTH runes,
+// and nothing reserved
+func Validate(name string) error {
+	if !utf8.ValidString(name) {
+		return errors.New("name is not valid UTF-8")
+	}
+
+	if length := utf8.RuneCountInString(name); length < MIN_LENGTH || length > MAX_LENGTH {
+		return fmt.Errorf("names are %d to %d characters", MIN_LENGTH, MAX_LENGTH)
+	}
+
+	var previous rune = ' '
+	for _, r := range name {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+		case separator(r):
+			if separator(previous) {
+				return errors.New("names start with a letter or digit and have one separator at a time")
+			}
+		default:
+			return errors.New("names may only use letters, digits, spaces, _, - and .")
+		}
+
+		previous = r
+	}
+
+	if separator(previous) {
+		return errors.New("names end with a letter or digit")
+	}
+
+	var folded string = strings.ToLower(strings.Map(func(r rune) rune {
+		if separator(r) {
+			return -1
+		}
+
+		return r
+	}, name))
+
+	for _, reserved := range RESERVED {
+		if folded == reserved {
+			return fmt.Errorf("%q is reserved", name)
+		}
+	}
+
+	for _, part := range RESERVED_PARTS {
+		if strings.Contains(folded, part) {
+			return fmt.Errorf("names may not contain %q", part)
+		}
+	}
+
+	return nil
+}
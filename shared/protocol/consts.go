@@ -1,15 +1,39 @@
 package protocol
 
+// Bump whenever a packet layout changes. Servers accept any version from PROTOCOL_MIN_VERSION up,
+// clients outside that range are rejected during the join handshake.
+const (
+	PROTOCOL_VERSION     uint16 = 8
+	PROTOCOL_MIN_VERSION uint16 = 1
+)
+
+const (
+	PROTOCOL_QUANTIZED_VERSION  uint16 = 3 // First version with camera relative, quantized entity positions
+	PROTOCOL_PREDICTION_VERSION uint16 = 4 // First version with sequenced inputs, one per tick, acknowledged in the view header
+	PROTOCOL_VIEW_TICK_VERSION  uint16 = 5 // First version where inputs say which server tick the client was drawing
+	PROTOCOL_INTEREST_VERSION   uint16 = 6 // First version with budgeted view updates, held back ships are listed after the deletes
+	PROTOCOL_ACCOUNTS_VERSION   uint16 = 7 // First version where the join can carry a session token
+	PROTOCOL_LOADOUT_VERSION    uint16 = 8 // First version where the join can pick squadron variants
+)
+
 const (
 	PACKET_CLIENTBOUND_KICK uint8 = iota
 	PACKET_CLIENTBOUND_MAP_UPDATE
 	PACKET_CLIENTBOUND_VIEW_UPDATE
 	PACKET_CLIENTBOUND_GUI_UPDATE
+	PACKET_CLIENTBOUND_CHAT
+	PACKET_CLIENTBOUND_JOIN_ACCEPT
+	PACKET_CLIENTBOUND_JOIN_REJECT
 )
 
+const JOIN_SHIP_SPECTATE uint8 = 0xFF // Requested ship ID for joining as a spectator
+
 const (
 	PACKET_SERVERBOUND_JOIN uint8 = iota
 	PACKET_SERVERBOUND_INPUT
+	PACKET_SERVERBOUND_SPECTATE
+	PACKET_SERVERBOUND_CONSUMABLE
+	PACKET_SERVERBOUND_CHAT
 )
 
 const (
@@ -22,7 +46,44 @@ const (
 	BITFLAG_MOUSE_MOVE
 )
 
+const (
+	SPECTATE_ACTION_NEXT uint8 = iota
+	SPECTATE_ACTION_PREVIOUS
+	SPECTATE_ACTION_TOGGLE_FREE_CAM
+	SPECTATE_ACTION_TOGGLE_ALL_FACTIONS
+	SPECTATE_ACTION_SET_FOV
+)
+
+const (
+	BITFLAG_SPECTATE_FREE_CAM uint8 = 1 << iota
+	BITFLAG_SPECTATE_ALL_FACTIONS
+)
+
+const (
+	BITFLAG_MAP_SHIP_SELF uint8 = 1 << iota
+	BITFLAG_MAP_SHIP_ALLIED
+)
+
+const MAP_UPDATE_INTERVAL int = 30 // Ticks between map updates
+
+const (
+	CHAT_CHANNEL_ALL uint8 = iota
+	CHAT_CHANNEL_FACTION
+	CHAT_CHANNEL_WHISPER
+	CHAT_CHANNEL_SYSTEM
+)
+
+const CHAT_MAX_LENGTH int = 200 // In runes
+
 const (
 	ENTITY_TYPE_DEFAULT uint8 = iota
 	ENTITY_TYPE_SHIP
+	ENTITY_TYPE_ISLAND
+)
+
+const (
+	BITFLAG_SHIP_UPDATE_POSITION uint8 = 1 << iota
+	BITFLAG_SHIP_UPDATE_SIZE
+	BITFLAG_SHIP_UPDATE_ROTATION
+	BITFLAG_SHIP_UPDATE_HEALTH
 )
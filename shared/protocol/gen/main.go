@@ -0,0 +1,357 @@
+// Generates Write/Read methods for every struct declared in the protocol schema file.
+//
+// Field encodings follow the Go type (uint8 -> U8, float32 -> F32, string -> StringUTF8, ...).
+// Slices are prefixed with their length and structs declared in the schema are encoded inline.
+// Every slice element must encode to at least one byte, decoded lengths are clamped to what is left
+// in the buffer. Read returns the reader's sticky error.
+// The `proto` tag accepts comma separated options:
+//
+//	len=u8|u16|u32  width of a slice length prefix in v1 (default u16), a varint from v2
+//	varint          unsigned integer written as a varint from v2, for IDs and counts
+//	if=<expr>       only encode the field when the Go expression holds, fields are referenced as p.Field
+//	since=N         only present from protocol version N
+//	until=N         only present up to and including protocol version N
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var basicTypes map[string]string = map[string]string{
+	"uint8":   "U8",
+	"uint16":  "U16",
+	"uint32":  "U32",
+	"uint64":  "U64",
+	"int8":    "I8",
+	"int16":   "I16",
+	"int32":   "I32",
+	"int64":   "I64",
+	"float32": "F32",
+	"float64": "F64",
+	"string":  "StringUTF8",
+}
+
+var varintTypes map[string]string = map[string]string{
+	"uint8":  "VarU8",
+	"uint16": "VarU16",
+	"uint32": "VarU32",
+	"uint64": "VarU64",
+}
+
+type (
+	field struct {
+		name      string
+		typ       ast.Expr
+		lenWidth  string
+		condition string
+		varint    bool
+		since     int
+		until     int
+	}
+
+	schema struct {
+		name   string
+		fields []*field
+	}
+)
+
+func parseTag(f *field, tag *ast.BasicLit) (err error) {
+	f.lenWidth = "U16"
+	if tag == nil {
+		return
+	}
+
+	var raw string
+	if raw, err = strconv.Unquote(tag.Value); err != nil {
+		return
+	}
+
+	for _, option := range strings.Split(reflect.StructTag(raw).Get("proto"), ",") {
+		if option = strings.TrimSpace(option); option == "" {
+			continue
+		}
+
+		key, value, _ := strings.Cut(option, "=")
+		switch key {
+		case "len":
+			if f.lenWidth = strings.ToUpper(value); f.lenWidth != "U8" && f.lenWidth != "U16" && f.lenWidth != "U32" {
+				return fmt.Errorf("unsupported length width %q", value)
+			}
+		case "if":
+			f.condition = value
+		case "since", "until":
+			var version int
+			if version, err = strconv.Atoi(value); err != nil || version < 1 {
+				return fmt.Errorf("invalid version %q", value)
+			}
+
+			if key == "since" {
+				f.since = version
+			} else {
+				f.until = version
+			}
+		case "varint":
+			if _, unsigned := varintTypes[typeNameOrEmpty(f.typ)]; !unsigned {
+				return fmt.Errorf("varint is only supported on unsigned integers")
+			}
+
+			f.varint = true
+		default:
+			return fmt.Errorf("unknown proto option %q", key)
+		}
+	}
+
+	return
+}
+
+func collect(file *ast.File) (schemas []*schema, err error) {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range gen.Specs {
+			var typeSpec *ast.TypeSpec = spec.(*ast.TypeSpec)
+			structType, isStruct := typeSpec.Type.(*ast.StructType)
+			if !isStruct {
+				continue
+			}
+
+			var s *schema = &schema{name: typeSpec.Name.Name}
+			for _, astField := range structType.Fields.List {
+				for _, name := range astField.Names {
+					var f *field = &field{name: name.Name, typ: astField.Type}
+					if err = parseTag(f, astField.Tag); err != nil {
+						return nil, fmt.Errorf("%s.%s: %w", s.name, name.Name, err)
+					}
+
+					s.fields = append(s.fields, f)
+				}
+			}
+
+			schemas = append(schemas, s)
+		}
+	}
+
+	return
+}
+
+func typeName(expr ast.Expr) (name string, err error) {
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return "", fmt.Errorf("unsupported type %T", expr)
+	}
+
+	return ident.Name, nil
+}
+
+func typeNameOrEmpty(expr ast.Expr) string {
+	name, _ := typeName(expr)
+	return name
+}
+
+func writeValue(out *bytes.Buffer, known map[string]bool, access string, typ ast.Expr, lenWidth string, varint bool, depth int) (err error) {
+	if array, isArray := typ.(*ast.ArrayType); isArray {
+		var index string = fmt.Sprintf("i%d", depth)
+		fmt.Fprintf(out, "w.SetVar%s(uint%s(len(%s)))\n", lenWidth, lenWidth[1:], access)
+		fmt.Fprintf(out, "for %s := range %s {\n", index, access)
+		if err = writeValue(out, known, access+"["+index+"]", array.Elt, "U16", false, depth+1); err != nil {
+			return
+		}
+
+		out.WriteString("}\n")
+		return
+	}
+
+	var name string
+	if name, err = typeName(typ); err != nil {
+		return
+	}
+
+	if varint {
+		fmt.Fprintf(out, "w.Set%s(%s)\n", varintTypes[name], access)
+		return
+	}
+
+	if method, basic := basicTypes[name]; basic {
+		fmt.Fprintf(out, "w.Set%s(%s)\n", method, access)
+		return
+	}
+
+	if !known[name] {
+		return fmt.Errorf("type %s is not declared in the schema", name)
+	}
+
+	fmt.Fprintf(out, "%s.Write(w)\n", access)
+	return
+}
+
+func readValue(out *bytes.Buffer, known map[string]bool, access string, typ ast.Expr, lenWidth string, varint bool, depth int) (err error) {
+	if array, isArray := typ.(*ast.ArrayType); isArray {
+		var elem string
+		if elem, err = typeName(array.Elt); err != nil {
+			return
+		}
+
+		var index string = fmt.Sprintf("i%d", depth)
+		fmt.Fprintf(out, "%s = make([]%s, r.Limit(int(r.GetVar%s())))\n", access, elem, lenWidth)
+		fmt.Fprintf(out, "for %s := range %s {\n", index, access)
+		if err = readValue(out, known, access+"["+index+"]", array.Elt, "U16", false, depth+1); err != nil {
+			return
+		}
+
+		out.WriteString("}\n")
+		return
+	}
+
+	var name string
+	if name, err = typeName(typ); err != nil {
+		return
+	}
+
+	if varint {
+		fmt.Fprintf(out, "%s = r.Get%s()\n", access, varintTypes[name])
+		return
+	}
+
+	if method, basic := basicTypes[name]; basic {
+		fmt.Fprintf(out, "%s = r.Get%s()\n", access, method)
+		return
+	}
+
+	if !known[name] {
+		return fmt.Errorf("type %s is not declared in the schema", name)
+	}
+
+	// Nested reads share the reader's sticky error, the outer Read reports it
+	fmt.Fprintf(out, "%s.Read(r)\n", access)
+	return
+}
+
+// The Go condition guarding a field, version checks read from the Writer or Reader named by codec
+func (f *field) guard(codec string) string {
+	var parts []string
+	if f.since != 0 {
+		parts = append(parts, fmt.Sprintf("%s.Version >= %d", codec, f.since))
+	}
+
+	if f.until != 0 {
+		parts = append(parts, fmt.Sprintf("%s.Version <= %d", codec, f.until))
+	}
+
+	if f.condition != "" {
+		if len(parts) > 0 {
+			parts = append(parts, "("+f.condition+")")
+		} else {
+			parts = append(parts, f.condition)
+		}
+	}
+
+	return strings.Join(parts, " && ")
+}
+
+// Emits every field of a struct, consecutive fields sharing a condition share a single if block
+func generateBody(out *bytes.Buffer, s *schema, codec, tail string, emit func(*field) error) (err error) {
+	var open string
+	for _, f := range s.fields {
+		if guard := f.guard(codec); guard != open {
+			if open != "" {
+				out.WriteString("}\n")
+			}
+
+			if open = guard; open != "" {
+				fmt.Fprintf(out, "if %s {\n", open)
+			}
+		}
+
+		if err = emit(f); err != nil {
+			return fmt.Errorf("%s.%s: %w", s.name, f.name, err)
+		}
+	}
+
+	if open != "" {
+		out.WriteString("}\n")
+	}
+
+	out.WriteString(tail + "}\n")
+	return
+}
+
+func generate(pkg string, schemas []*schema) (source []byte, err error) {
+	var (
+		out   *bytes.Buffer   = new(bytes.Buffer)
+		known map[string]bool = make(map[string]bool)
+	)
+
+	for _, s := range schemas {
+		known[s.name] = true
+	}
+
+	fmt.Fprintf(out, "// Code generated by shared/protocol/gen; DO NOT EDIT.\n\npackage %s\n", pkg)
+
+	for _, s := range schemas {
+		fmt.Fprintf(out, "\nfunc (p *%s) Write(w *Writer) {\n", s.name)
+		if err = generateBody(out, s, "w", "", func(f *field) error {
+			return writeValue(out, known, "p."+f.name, f.typ, f.lenWidth, f.varint, 0)
+		}); err != nil {
+			return
+		}
+
+		fmt.Fprintf(out, "\nfunc (p *%s) Read(r *Reader) error {\n", s.name)
+		if err = generateBody(out, s, "r", "return r.Err()\n", func(f *field) error {
+			return readValue(out, known, "p."+f.name, f.typ, f.lenWidth, f.varint, 0)
+		}); err != nil {
+			return
+		}
+	}
+
+	return format.Source(out.Bytes())
+}
+
+func main() {
+	var (
+		input  *string = flag.String("in", "packets.go", "Schema file")
+		output *string = flag.String("out", "packets_gen.go", "Generated file")
+	)
+
+	flag.Parse()
+
+	var (
+		fileSet *token.FileSet = token.NewFileSet()
+		file    *ast.File
+		schemas []*schema
+		source  []byte
+		err     error
+	)
+
+	if file, err = parser.ParseFile(fileSet, *input, nil, parser.SkipObjectResolution); err != nil {
+		fmt.Fprintf(os.Stderr, "parse schema: %v\n", err)
+		os.Exit(1)
+	}
+
+	if schemas, err = collect(file); err != nil {
+		fmt.Fprintf(os.Stderr, "read schema: %v\n", err)
+		os.Exit(1)
+	}
+
+	if source, err = generate(file.Name.Name, schemas); err != nil {
+		fmt.Fprintf(os.Stderr, "generate: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err = os.WriteFile(*output, source, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "write %s: %v\n", *output, err)
+		os.Exit(1)
+	}
+}
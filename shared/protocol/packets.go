@@ -0,0 +1,196 @@
+package protocol
+
+//go:generate go run ./gen -in packets.go -out packets_gen.go
+
+// Every struct in this file is part of the wire format. Write/Read methods are generated into
+// packets_gen.go, so both sides always agree on the layout. Run `go generate ./shared/protocol`
+// after changing anything here, and bump PROTOCOL_VERSION if the layout changed.
+//
+// The packet ID byte is not part of these structs, it is written by whoever sends the packet.
+
+type (
+	Point struct {
+		X, Y float32
+	}
+
+	// PACKET_SERVERBOUND_JOIN, preceded by the U16 protocol version the client speaks. That version
+	// picks the encoding of the rest of the join and everything after it on the connection.
+	Join struct {
+		Name    string // Only used by guests, players with a token play under their account's name
+		ShipID  uint8  // JOIN_SHIP_SPECTATE to join without a body
+		Room    string
+		Token   string  `proto:"since=7"`        // Session token from logging in, empty to join as a guest
+		Loadout []uint8 `proto:"since=8,len=u8"` // Variant index per squadron slot, slots past the end keep their default
+	}
+
+	// PACKET_SERVERBOUND_INPUT
+	// From v4 a body sends one every tick, numbered so the server can acknowledge it
+	Input struct {
+		Flags    uint8
+		MouseX   float32 `proto:"if=p.Flags&BITFLAG_MOUSE_MOVE != 0"`
+		MouseY   float32 `proto:"if=p.Flags&BITFLAG_MOUSE_MOVE != 0"`
+		Sequence uint32  `proto:"varint,since=4"`
+		ViewTick uint32  `proto:"varint,since=5"` // Server tick being drawn, aimed shots are checked against it
+	}
+
+	// PACKET_SERVERBOUND_SPECTATE
+	SpectateAction struct {
+		Action uint8
+		FOV    float32 `proto:"if=p.Action == SPECTATE_ACTION_SET_FOV"`
+	}
+
+	// PACKET_SERVERBOUND_CONSUMABLE
+	ConsumableActivate struct {
+		Slot uint8
+	}
+
+	// PACKET_SERVERBOUND_CHAT
+	ChatSend struct {
+		Channel uint8
+		Target  string // Only used for whispers
+		Message string
+	}
+
+	// PACKET_CLIENTBOUND_CHAT
+	ChatReceive struct {
+		Channel uint8
+		Sender  string
+		Message string
+	}
+
+	// PACKET_CLIENTBOUND_JOIN_ACCEPT
+	JoinAccept struct {
+		PlayerID          uint32 `proto:"varint"`
+		EntityID          uint64 `proto:"varint"` // 0 when spectating
+		TickRate          uint8
+		DefinitionsHash   uint32
+		PositionPrecision float32 `proto:"since=3"` // World units per step of a quantized offset
+	}
+
+	// PACKET_CLIENTBOUND_JOIN_REJECT and PACKET_CLIENTBOUND_KICK
+	Disconnect struct {
+		Reason string
+	}
+
+	// Start of PACKET_CLIENTBOUND_VIEW_UPDATE, followed by the entity and delete lists
+	ViewHeader struct {
+		Time           uint32
+		X, Y           float32
+		FOV            float32
+		EntityID       uint64 `proto:"varint"`
+		Spectating     uint8
+		SpectateTarget uint64 `proto:"varint,if=p.Spectating != 0"`
+		SpectateFlags  uint8  `proto:"if=p.Spectating != 0"`
+
+		// Own ship after the last input the server applied, X and Y above are its position
+		InputAck  uint32  `proto:"varint,since=4,if=p.EntityID != 0"`
+		VelocityX float32 `proto:"since=4,if=p.EntityID != 0"`
+		VelocityY float32 `proto:"since=4,if=p.EntityID != 0"`
+		Rotation  float32 `proto:"since=4,if=p.EntityID != 0"`
+	}
+
+	// From v3 positions are offsets from the camera center in the view header, see QuantizeOffset
+	ShipCreate struct {
+		X, Y     float32 `proto:"until=2"`
+		OffsetX  uint16  `proto:"since=3"`
+		OffsetY  uint16  `proto:"since=3"`
+		Size     float32
+		Rotation float32 `proto:"until=2"`
+		Angle    uint16  `proto:"since=3"` // QuantizeAngle12
+		Name     string
+		Hull     []Point
+		ShipID   uint8
+		Health   float32
+	}
+
+	ShipUpdate struct {
+		Flags    uint8
+		X, Y     float32 `proto:"until=2,if=p.Flags&BITFLAG_SHIP_UPDATE_POSITION != 0"`
+		OffsetX  uint16  `proto:"since=3,if=p.Flags&BITFLAG_SHIP_UPDATE_POSITION != 0"`
+		OffsetY  uint16  `proto:"since=3,if=p.Flags&BITFLAG_SHIP_UPDATE_POSITION != 0"`
+		Size     float32 `proto:"if=p.Flags&BITFLAG_SHIP_UPDATE_SIZE != 0"`
+		Rotation float32 `proto:"until=2,if=p.Flags&BITFLAG_SHIP_UPDATE_ROTATION != 0"`
+		Angle    uint16  `proto:"since=3,if=p.Flags&BITFLAG_SHIP_UPDATE_ROTATION != 0"`
+		Health   float32 `proto:"if=p.Flags&BITFLAG_SHIP_UPDATE_HEALTH != 0"`
+	}
+
+	IslandCreate struct {
+		X, Y     float32
+		Size     float32
+		Rotation float32
+		Hull     []Point
+	}
+
+	GUISquadron struct {
+		PlaneID     uint16 `proto:"varint"`
+		Planes      uint8
+		HangarSize  uint8
+		LaunchTimer uint16 // Deciseconds
+		RegenTimer  uint16 // Deciseconds
+	}
+
+	GUIConsumable struct {
+		ConsumableID uint8
+		Charges      uint8  // 0xFF when unlimited
+		Cooldown     uint16 // Deciseconds
+		Active       uint16 // Deciseconds
+	}
+
+	// PACKET_CLIENTBOUND_GUI_UPDATE
+	GUIUpdate struct {
+		HasBody     uint8
+		ShipID      uint8           `proto:"if=p.HasBody != 0"`
+		Health      float32         `proto:"if=p.HasBody != 0"`
+		MaxHealth   float32         `proto:"if=p.HasBody != 0"`
+		Speed       float32         `proto:"if=p.HasBody != 0"`
+		MaxSpeed    float32         `proto:"if=p.HasBody != 0"`
+		Squadrons   []GUISquadron   `proto:"len=u8,if=p.HasBody != 0"`
+		Consumables []GUIConsumable `proto:"len=u8,if=p.HasBody != 0"`
+		Score       uint32
+		Elapsed     uint32 // Seconds
+		MatchLength uint32 // Seconds, 0 when unlimited
+	}
+
+	// Coordinates are quantized against the map extent
+	MapPoint struct {
+		X, Y uint16
+	}
+
+	MapIsland struct {
+		Points []MapPoint `proto:"len=u8"`
+	}
+
+	MapFaction struct {
+		ID      uint32 `proto:"varint"`
+		R, G, B uint8
+		Name    string
+	}
+
+	MapObjective struct {
+		Position MapPoint
+		Radius   float32
+		Owner    uint32 `proto:"varint"`
+		Capturer uint32 `proto:"varint"`
+		Progress uint8
+		Name     string
+	}
+
+	MapShip struct {
+		Position       MapPoint
+		Rotation       uint8
+		Faction        uint32 `proto:"varint"`
+		Classification uint8
+		Flags          uint8
+	}
+
+	// PACKET_CLIENTBOUND_MAP_UPDATE
+	MapUpdate struct {
+		Time       uint32
+		Extent     float32
+		HasStatic  uint8
+		Islands    []MapIsland `proto:"if=p.HasStatic != 0"`
+		Factions   []MapFaction
+		Objectives []MapObjective
+		Ships      []MapShip
+	}
+)
@@ -0,0 +1,521 @@
+// Code generated by shared/protocol/gen; DO NOT EDIT.
+
+package protocol
+
+func (p *Point) Write(w *Writer) {
+	w.SetF32(p.X)
+	w.SetF32(p.Y)
+}
+
+func (p *Point) Read(r *Reader) error {
+	p.X = r.GetF32()
+	p.Y = r.GetF32()
+	return r.Err()
+}
+
+func (p *Join) Write(w *Writer) {
+	w.SetStringUTF8(p.Name)
+	w.SetU8(p.ShipID)
+	w.SetStringUTF8(p.Room)
+	if w.Version >= 7 {
+		w.SetStringUTF8(p.Token)
+	}
+	if w.Version >= 8 {
+		w.SetVarU8(uint8(len(p.Loadout)))
+		for i0 := range p.Loadout {
+			w.SetU8(p.Loadout[i0])
+		}
+	}
+}
+
+func (p *Join) Read(r *Reader) error {
+	p.Name = r.GetStringUTF8()
+	p.ShipID = r.GetU8()
+	p.Room = r.GetStringUTF8()
+	if r.Version >= 7 {
+		p.Token = r.GetStringUTF8()
+	}
+	if r.Version >= 8 {
+		p.Loadout = make([]uint8, r.Limit(int(r.GetVarU8())))
+		for i0 := range p.Loadout {
+			p.Loadout[i0] = r.GetU8()
+		}
+	}
+	return r.Err()
+}
+
+func (p *Input) Write(w *Writer) {
+	w.SetU8(p.Flags)
+	if p.Flags&BITFLAG_MOUSE_MOVE != 0 {
+		w.SetF32(p.MouseX)
+		w.SetF32(p.MouseY)
+	}
+	if w.Version >= 4 {
+		w.SetVarU32(p.Sequence)
+	}
+	if w.Version >= 5 {
+		w.SetVarU32(p.ViewTick)
+	}
+}
+
+func (p *Input) Read(r *Reader) error {
+	p.Flags = r.GetU8()
+	if p.Flags&BITFLAG_MOUSE_MOVE != 0 {
+		p.MouseX = r.GetF32()
+		p.MouseY = r.GetF32()
+	}
+	if r.Version >= 4 {
+		p.Sequence = r.GetVarU32()
+	}
+	if r.Version >= 5 {
+		p.ViewTick = r.GetVarU32()
+	}
+	return r.Err()
+}
+
+func (p *SpectateAction) Write(w *Writer) {
+	w.SetU8(p.Action)
+	if p.Action == SPECTATE_ACTION_SET_FOV {
+		w.SetF32(p.FOV)
+	}
+}
+
+func (p *SpectateAction) Read(r *Reader) error {
+	p.Action = r.GetU8()
+	if p.Action == SPECTATE_ACTION_SET_FOV {
+		p.FOV = r.GetF32()
+	}
+	return r.Err()
+}
+
+func (p *ConsumableActivate) Write(w *Writer) {
+	w.SetU8(p.Slot)
+}
+
+func (p *ConsumableActivate) Read(r *Reader) error {
+	p.Slot = r.GetU8()
+	return r.Err()
+}
+
+func (p *ChatSend) Write(w *Writer) {
+	w.SetU8(p.Channel)
+	w.SetStringUTF8(p.Target)
+	w.SetStringUTF8(p.Message)
+}
+
+func (p *ChatSend) Read(r *Reader) error {
+	p.Channel = r.GetU8()
+	p.Target = r.GetStringUTF8()
+	p.Message = r.GetStringUTF8()
+	return r.Err()
+}
+
+func (p *ChatReceive) Write(w *Writer) {
+	w.SetU8(p.Channel)
+	w.SetStringUTF8(p.Sender)
+	w.SetStringUTF8(p.Message)
+}
+
+func (p *ChatReceive) Read(r *Reader) error {
+	p.Channel = r.GetU8()
+	p.Sender = r.GetStringUTF8()
+	p.Message = r.GetStringUTF8()
+	return r.Err()
+}
+
+func (p *JoinAccept) Write(w *Writer) {
+	w.SetVarU32(p.PlayerID)
+	w.SetVarU64(p.EntityID)
+	w.SetU8(p.TickRate)
+	w.SetU32(p.DefinitionsHash)
+	if w.Version >= 3 {
+		w.SetF32(p.PositionPrecision)
+	}
+}
+
+func (p *JoinAccept) Read(r *Reader) error {
+	p.PlayerID = r.GetVarU32()
+	p.EntityID = r.GetVarU64()
+	p.TickRate = r.GetU8()
+	p.DefinitionsHash = r.GetU32()
+	if r.Version >= 3 {
+		p.PositionPrecision = r.GetF32()
+	}
+	return r.Err()
+}
+
+func (p *Disconnect) Write(w *Writer) {
+	w.SetStringUTF8(p.Reason)
+}
+
+func (p *Disconnect) Read(r *Reader) error {
+	p.Reason = r.GetStringUTF8()
+	return r.Err()
+}
+
+func (p *ViewHeader) Write(w *Writer) {
+	w.SetU32(p.Time)
+	w.SetF32(p.X)
+	w.SetF32(p.Y)
+	w.SetF32(p.FOV)
+	w.SetVarU64(p.EntityID)
+	w.SetU8(p.Spectating)
+	if p.Spectating != 0 {
+		w.SetVarU64(p.SpectateTarget)
+		w.SetU8(p.SpectateFlags)
+	}
+	if w.Version >= 4 && (p.EntityID != 0) {
+		w.SetVarU32(p.InputAck)
+		w.SetF32(p.VelocityX)
+		w.SetF32(p.VelocityY)
+		w.SetF32(p.Rotation)
+	}
+}
+
+func (p *ViewHeader) Read(r *Reader) error {
+	p.Time = r.GetU32()
+	p.X = r.GetF32()
+	p.Y = r.GetF32()
+	p.FOV = r.GetF32()
+	p.EntityID = r.GetVarU64()
+	p.Spectating = r.GetU8()
+	if p.Spectating != 0 {
+		p.SpectateTarget = r.GetVarU64()
+		p.SpectateFlags = r.GetU8()
+	}
+	if r.Version >= 4 && (p.EntityID != 0) {
+		p.InputAck = r.GetVarU32()
+		p.VelocityX = r.GetF32()
+		p.VelocityY = r.GetF32()
+		p.Rotation = r.GetF32()
+	}
+	return r.Err()
+}
+
+func (p *ShipCreate) Write(w *Writer) {
+	if w.Version <= 2 {
+		w.SetF32(p.X)
+		w.SetF32(p.Y)
+	}
+	if w.Version >= 3 {
+		w.SetU16(p.OffsetX)
+		w.SetU16(p.OffsetY)
+	}
+	w.SetF32(p.Size)
+	if w.Version <= 2 {
+		w.SetF32(p.Rotation)
+	}
+	if w.Version >= 3 {
+		w.SetU16(p.Angle)
+	}
+	w.SetStringUTF8(p.Name)
+	w.SetVarU16(uint16(len(p.Hull)))
+	for i0 := range p.Hull {
+		p.Hull[i0].Write(w)
+	}
+	w.SetU8(p.ShipID)
+	w.SetF32(p.Health)
+}
+
+func (p *ShipCreate) Read(r *Reader) error {
+	if r.Version <= 2 {
+		p.X = r.GetF32()
+		p.Y = r.GetF32()
+	}
+	if r.Version >= 3 {
+		p.OffsetX = r.GetU16()
+		p.OffsetY = r.GetU16()
+	}
+	p.Size = r.GetF32()
+	if r.Version <= 2 {
+		p.Rotation = r.GetF32()
+	}
+	if r.Version >= 3 {
+		p.Angle = r.GetU16()
+	}
+	p.Name = r.GetStringUTF8()
+	p.Hull = make([]Point, r.Limit(int(r.GetVarU16())))
+	for i0 := range p.Hull {
+		p.Hull[i0].Read(r)
+	}
+	p.ShipID = r.GetU8()
+	p.Health = r.GetF32()
+	return r.Err()
+}
+
+func (p *ShipUpdate) Write(w *Writer) {
+	w.SetU8(p.Flags)
+	if w.Version <= 2 && (p.Flags&BITFLAG_SHIP_UPDATE_POSITION != 0) {
+		w.SetF32(p.X)
+		w.SetF32(p.Y)
+	}
+	if w.Version >= 3 && (p.Flags&BITFLAG_SHIP_UPDATE_POSITION != 0) {
+		w.SetU16(p.OffsetX)
+		w.SetU16(p.OffsetY)
+	}
+	if p.Flags&BITFLAG_SHIP_UPDATE_SIZE != 0 {
+		w.SetF32(p.Size)
+	}
+	if w.Version <= 2 && (p.Flags&BITFLAG_SHIP_UPDATE_ROTATION != 0) {
+		w.SetF32(p.Rotation)
+	}
+	if w.Version >= 3 && (p.Flags&BITFLAG_SHIP_UPDATE_ROTATION != 0) {
+		w.SetU16(p.Angle)
+	}
+	if p.Flags&BITFLAG_SHIP_UPDATE_HEALTH != 0 {
+		w.SetF32(p.Health)
+	}
+}
+
+func (p *ShipUpdate) Read(r *Reader) error {
+	p.Flags = r.GetU8()
+	if r.Version <= 2 && (p.Flags&BITFLAG_SHIP_UPDATE_POSITION != 0) {
+		p.X = r.GetF32()
+		p.Y = r.GetF32()
+	}
+	if r.Version >= 3 && (p.Flags&BITFLAG_SHIP_UPDATE_POSITION != 0) {
+		p.OffsetX = r.GetU16()
+		p.OffsetY = r.GetU16()
+	}
+	if p.Flags&BITFLAG_SHIP_UPDATE_SIZE != 0 {
+		p.Size = r.GetF32()
+	}
+	if r.Version <= 2 && (p.Flags&BITFLAG_SHIP_UPDATE_ROTATION != 0) {
+		p.Rotation = r.GetF32()
+	}
+	if r.Version >= 3 && (p.Flags&BITFLAG_SHIP_UPDATE_ROTATION != 0) {
+		p.Angle = r.GetU16()
+	}
+	if p.Flags&BITFLAG_SHIP_UPDATE_HEALTH != 0 {
+		p.Health = r.GetF32()
+	}
+	return r.Err()
+}
+
+func (p *IslandCreate) Write(w *Writer) {
+	w.SetF32(p.X)
+	w.SetF32(p.Y)
+	w.SetF32(p.Size)
+	w.SetF32(p.Rotation)
+	w.SetVarU16(uint16(len(p.Hull)))
+	for i0 := range p.Hull {
+		p.Hull[i0].Write(w)
+	}
+}
+
+func (p *IslandCreate) Read(r *Reader) error {
+	p.X = r.GetF32()
+	p.Y = r.GetF32()
+	p.Size = r.GetF32()
+	p.Rotation = r.GetF32()
+	p.Hull = make([]Point, r.Limit(int(r.GetVarU16())))
+	for i0 := range p.Hull {
+		p.Hull[i0].Read(r)
+	}
+	return r.Err()
+}
+
+func (p *GUISquadron) Write(w *Writer) {
+	w.SetVarU16(p.PlaneID)
+	w.SetU8(p.Planes)
+	w.SetU8(p.HangarSize)
+	w.SetU16(p.LaunchTimer)
+	w.SetU16(p.RegenTimer)
+}
+
+func (p *GUISquadron) Read(r *Reader) error {
+	p.PlaneID = r.GetVarU16()
+	p.Planes = r.GetU8()
+	p.HangarSize = r.GetU8()
+	p.LaunchTimer = r.GetU16()
+	p.RegenTimer = r.GetU16()
+	return r.Err()
+}
+
+func (p *GUIConsumable) Write(w *Writer) {
+	w.SetU8(p.ConsumableID)
+	w.SetU8(p.Charges)
+	w.SetU16(p.Cooldown)
+	w.SetU16(p.Active)
+}
+
+func (p *GUIConsumable) Read(r *Reader) error {
+	p.ConsumableID = r.GetU8()
+	p.Charges = r.GetU8()
+	p.Cooldown = r.GetU16()
+	p.Active = r.GetU16()
+	return r.Err()
+}
+
+func (p *GUIUpdate) Write(w *Writer) {
+	w.SetU8(p.HasBody)
+	if p.HasBody != 0 {
+		w.SetU8(p.ShipID)
+		w.SetF32(p.Health)
+		w.SetF32(p.MaxHealth)
+		w.SetF32(p.Speed)
+		w.SetF32(p.MaxSpeed)
+		w.SetVarU8(uint8(len(p.Squadrons)))
+		for i0 := range p.Squadrons {
+			p.Squadrons[i0].Write(w)
+		}
+		w.SetVarU8(uint8(len(p.Consumables)))
+		for i0 := range p.Consumables {
+			p.Consumables[i0].Write(w)
+		}
+	}
+	w.SetU32(p.Score)
+	w.SetU32(p.Elapsed)
+	w.SetU32(p.MatchLength)
+}
+
+func (p *GUIUpdate) Read(r *Reader) error {
+	p.HasBody = r.GetU8()
+	if p.HasBody != 0 {
+		p.ShipID = r.GetU8()
+		p.Health = r.GetF32()
+		p.MaxHealth = r.GetF32()
+		p.Speed = r.GetF32()
+		p.MaxSpeed = r.GetF32()
+		p.Squadrons = make([]GUISquadron, r.Limit(int(r.GetVarU8())))
+		for i0 := range p.Squadrons {
+			p.Squadrons[i0].Read(r)
+		}
+		p.Consumables = make([]GUIConsumable, r.Limit(int(r.GetVarU8())))
+		for i0 := range p.Consumables {
+			p.Consumables[i0].Read(r)
+		}
+	}
+	p.Score = r.GetU32()
+	p.Elapsed = r.GetU32()
+	p.MatchLength = r.GetU32()
+	return r.Err()
+}
+
+func (p *MapPoint) Write(w *Writer) {
+	w.SetU16(p.X)
+	w.SetU16(p.Y)
+}
+
+func (p *MapPoint) Read(r *Reader) error {
+	p.X = r.GetU16()
+	p.Y = r.GetU16()
+	return r.Err()
+}
+
+func (p *MapIsland) Write(w *Writer) {
+	w.SetVarU8(uint8(len(p.Points)))
+	for i0 := range p.Points {
+		p.Points[i0].Write(w)
+	}
+}
+
+func (p *MapIsland) Read(r *Reader) error {
+	p.Points = make([]MapPoint, r.Limit(int(r.GetVarU8())))
+	for i0 := range p.Points {
+		p.Points[i0].Read(r)
+	}
+	return r.Err()
+}
+
+func (p *MapFaction) Write(w *Writer) {
+	w.SetVarU32(p.ID)
+	w.SetU8(p.R)
+	w.SetU8(p.G)
+	w.SetU8(p.B)
+	w.SetStringUTF8(p.Name)
+}
+
+func (p *MapFaction) Read(r *Reader) error {
+	p.ID = r.GetVarU32()
+	p.R = r.GetU8()
+	p.G = r.GetU8()
+	p.B = r.GetU8()
+	p.Name = r.GetStringUTF8()
+	return r.Err()
+}
+
+func (p *MapObjective) Write(w *Writer) {
+	p.Position.Write(w)
+	w.SetF32(p.Radius)
+	w.SetVarU32(p.Owner)
+	w.SetVarU32(p.Capturer)
+	w.SetU8(p.Progress)
+	w.SetStringUTF8(p.Name)
+}
+
+func (p *MapObjective) Read(r *Reader) error {
+	p.Position.Read(r)
+	p.Radius = r.GetF32()
+	p.Owner = r.GetVarU32()
+	p.Capturer = r.GetVarU32()
+	p.Progress = r.GetU8()
+	p.Name = r.GetStringUTF8()
+	return r.Err()
+}
+
+func (p *MapShip) Write(w *Writer) {
+	p.Position.Write(w)
+	w.SetU8(p.Rotation)
+	w.SetVarU32(p.Faction)
+	w.SetU8(p.Classification)
+	w.SetU8(p.Flags)
+}
+
+func (p *MapShip) Read(r *Reader) error {
+	p.Position.Read(r)
+	p.Rotation = r.GetU8()
+	p.Faction = r.GetVarU32()
+	p.Classification = r.GetU8()
+	p.Flags = r.GetU8()
+	return r.Err()
+}
+
+func (p *MapUpdate) Write(w *Writer) {
+	w.SetU32(p.Time)
+	w.SetF32(p.Extent)
+	w.SetU8(p.HasStatic)
+	if p.HasStatic != 0 {
+		w.SetVarU16(uint16(len(p.Islands)))
+		for i0 := range p.Islands {
+			p.Islands[i0].Write(w)
+		}
+	}
+	w.SetVarU16(uint16(len(p.Factions)))
+	for i0 := range p.Factions {
+		p.Factions[i0].Write(w)
+	}
+	w.SetVarU16(uint16(len(p.Objectives)))
+	for i0 := range p.Objectives {
+		p.Objectives[i0].Write(w)
+	}
+	w.SetVarU16(uint16(len(p.Ships)))
+	for i0 := range p.Ships {
+		p.Ships[i0].Write(w)
+	}
+}
+
+func (p *MapUpdate) Read(r *Reader) error {
+	p.Time = r.GetU32()
+	p.Extent = r.GetF32()
+	p.HasStatic = r.GetU8()
+	if p.HasStatic != 0 {
+		p.Islands = make([]MapIsland, r.Limit(int(r.GetVarU16())))
+		for i0 := range p.Islands {
+			p.Islands[i0].Read(r)
+		}
+	}
+	p.Factions = make([]MapFaction, r.Limit(int(r.GetVarU16())))
+	for i0 := range p.Factions {
+		p.Factions[i0].Read(r)
+	}
+	p.Objectives = make([]MapObjective, r.Limit(int(r.GetVarU16())))
+	for i0 := range p.Objectives {
+		p.Objectives[i0].Read(r)
+	}
+	p.Ships = make([]MapShip, r.Limit(int(r.GetVarU16())))
+	for i0 := range p.Ships {
+		p.Ships[i0].Read(r)
+	}
+	return r.Err()
+}
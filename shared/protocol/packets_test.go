@@ -0,0 +1,208 @@
+package protocol
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type packet interface {
+	Write(w *Writer)
+	Read(r *Reader) error
+}
+
+// One value per case, every field set so a field the codec drops or misplaces shows up. Structs with
+// `if=` fields get a second case with the condition off and those fields left zero.
+var samples []struct {
+	name   string
+	packet packet
+} = []struct {
+	name   string
+	packet packet
+}{
+	{"Point", &Point{X: 1.5, Y: -2.25}},
+	{"Join", &Join{Name: "Kapitan", ShipID: 3, Room: "default", Token: "0123456789abcdef", Loadout: []uint8{0, 1, 2}}},
+	{"Input", &Input{Flags: BITFLAG_INPUT_UP | BITFLAG_MOUSE_MOVE, MouseX: 120, MouseY: -40, Sequence: 300, ViewTick: 70000}},
+	{"Input/no mouse", &Input{Flags: BITFLAG_INPUT_UP, Sequence: 301, ViewTick: 70001}},
+	{"SpectateAction", &SpectateAction{Action: SPECTATE_ACTION_SET_FOV, FOV: 2400}},
+	{"SpectateAction/cycle", &SpectateAction{Action: SPECTATE_ACTION_NEXT}},
+	{"ConsumableActivate", &ConsumableActivate{Slot: 2}},
+	{"ChatSend", &ChatSend{Channel: CHAT_CHANNEL_WHISPER, Target: "Admiral", Message: "hello"}},
+	{"ChatReceive", &ChatReceive{Channel: CHAT_CHANNEL_WHISPER, Sender: "Admiral", Message: "hello back"}},
+	{"JoinAccept", &JoinAccept{PlayerID: 1000, EntityID: 1 << 40, TickRate: 30, DefinitionsHash: 0xDEADBEEF, PositionPrecision: 0.125}},
+	{"Disconnect", &Disconnect{Reason: "kicked"}},
+	{"ViewHeader", &ViewHeader{
+		Time: 99, X: 10, Y: -10, FOV: 1800, EntityID: 7, Spectating: 1, SpectateTarget: 9, SpectateFlags: 3,
+		InputAck: 42, VelocityX: 1.5, VelocityY: -0.5, Rotation: 3.25,
+	}},
+	{"ViewHeader/bodiless", &ViewHeader{Time: 99, X: 10, Y: -10, FOV: 1800}},
+	{"ShipCreate", &ShipCreate{
+		X: 100, Y: -100, OffsetX: 0x8010, OffsetY: 0x7FF0, Size: 64, Rotation: 1.25, Angle: 2048,
+		Name: "Colossus", Hull: []Point{{X: 1, Y: 2}, {X: -3, Y: 4}}, ShipID: 1, Health: 0.75,
+	}},
+	{"ShipUpdate", &ShipUpdate{
+		X: 100, Y: -100, OffsetX: 0x8010, OffsetY: 0x7FF0, Size: 64, Rotation: 1.25, Angle: 2048, Health: 0.5,
+		Flags: BITFLAG_SHIP_UPDATE_POSITION | BITFLAG_SHIP_UPDATE_SIZE | BITFLAG_SHIP_UPDATE_ROTATION | BITFLAG_SHIP_UPDATE_HEALTH,
+	}},
+	{"ShipUpdate/health only", &ShipUpdate{Flags: BITFLAG_SHIP_UPDATE_HEALTH, Health: 0.25}},
+	{"IslandCreate", &IslandCreate{X: 5, Y: 6, Size: 300, Rotation: 0.5, Hull: []Point{{X: 1, Y: 1}, {X: 2, Y: 0}, {X: 0, Y: 2}}}},
+	{"GUISquadron", &GUISquadron{PlaneID: 300, Planes: 6, HangarSize: 12, LaunchTimer: 15, RegenTimer: 200}},
+	{"GUIConsumable", &GUIConsumable{ConsumableID: 1, Charges: 0xFF, Cooldown: 30, Active: 5}},
+	{"GUIUpdate", &GUIUpdate{
+		HasBody: 1, ShipID: 1, Health: 900, MaxHealth: 1000, Speed: 4, MaxSpeed: 6,
+		Score: 1200, Elapsed: 60, MatchLength: 900,
+		Squadrons:   []GUISquadron{{PlaneID: 1, Planes: 6, HangarSize: 12, LaunchTimer: 10, RegenTimer: 20}},
+		Consumables: []GUIConsumable{{ConsumableID: 1, Charges: 2, Cooldown: 30, Active: 5}},
+	}},
+	{"GUIUpdate/bodiless", &GUIUpdate{Score: 1200, Elapsed: 60}},
+	{"MapPoint", &MapPoint{X: 0x1234, Y: 0xFEDC}},
+	{"MapIsland", &MapIsland{Points: []MapPoint{{X: 1, Y: 2}, {X: 3, Y: 4}}}},
+	{"MapFaction", &MapFaction{ID: 500, R: 255, G: 128, B: 1, Name: "Red"}},
+	{"MapObjective", &MapObjective{Position: MapPoint{X: 10, Y: 20}, Radius: 400, Owner: 1, Capturer: 2, Progress: 128, Name: "Alpha"}},
+	{"MapShip", &MapShip{Position: MapPoint{X: 10, Y: 20}, Rotation: 64, Faction: 2, Classification: 3, Flags: BITFLAG_MAP_SHIP_SELF}},
+	{"MapUpdate", &MapUpdate{
+		Time: 99, Extent: 8192, HasStatic: 1,
+		Islands:    []MapIsland{{Points: []MapPoint{{X: 1, Y: 2}, {X: 3, Y: 4}}}},
+		Factions:   []MapFaction{{ID: 1, R: 255, Name: "Red"}, {ID: 2, B: 255, Name: "Blue"}},
+		Objectives: []MapObjective{{Radius: 400, Owner: 1, Name: "Alpha"}},
+		Ships:      []MapShip{{Faction: 1, Flags: BITFLAG_MAP_SHIP_SELF}},
+	}},
+	{"MapUpdate/dynamic", &MapUpdate{Time: 100, Extent: 8192, Factions: []MapFaction{}, Objectives: []MapObjective{}, Ships: []MapShip{{Faction: 2}}}},
+}
+
+// Whether a field with this `proto` tag is on the wire in the version
+func presentIn(tag string, version uint16) bool {
+	for _, option := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+		var bound, err = strconv.Atoi(value)
+		switch {
+		case err != nil:
+		case key == "since" && version < uint16(bound):
+			return false
+		case key == "until" && version > uint16(bound):
+			return false
+		}
+	}
+
+	return true
+}
+
+// Zeroes every field the version does not carry, including inside nested structs and slices
+func dropAbsent(value reflect.Value, version uint16) {
+	switch value.Kind() {
+	case reflect.Struct:
+		for i := range value.NumField() {
+			if !presentIn(value.Type().Field(i).Tag.Get("proto"), version) {
+				value.Field(i).SetZero()
+				continue
+			}
+
+			dropAbsent(value.Field(i), version)
+		}
+	case reflect.Slice:
+		for i := range value.Len() {
+			dropAbsent(value.Index(i), version)
+		}
+	}
+}
+
+// What the sample should decode to in a version
+func expected(sample packet, version uint16) packet {
+	var copied reflect.Value = reflect.New(reflect.TypeOf(sample).Elem())
+	copied.Elem().Set(reflect.ValueOf(sample).Elem())
+
+	// Slices are shared with the sample, copy them before zeroing anything inside
+	var deep func(value reflect.Value)
+	deep = func(value reflect.Value) {
+		switch value.Kind() {
+		case reflect.Struct:
+			for i := range value.NumField() {
+				deep(value.Field(i))
+			}
+		case reflect.Slice:
+			if value.IsNil() {
+				return
+			}
+
+			var clone reflect.Value = reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+			reflect.Copy(clone, value)
+			value.Set(clone)
+			for i := range clone.Len() {
+				deep(clone.Index(i))
+			}
+		}
+	}
+
+	deep(copied.Elem())
+	dropAbsent(copied.Elem(), version)
+	return copied.Interface().(packet)
+}
+
+func roundTrip(t *testing.T, sample packet, version uint16) {
+	t.Helper()
+
+	var writer *Writer = NewWriter(version)
+	sample.Write(writer)
+
+	var (
+		reader  *Reader = NewReader(writer.GetBytes())
+		decoded packet  = reflect.New(reflect.TypeOf(sample).Elem()).Interface().(packet)
+	)
+
+	reader.Version = version
+	if err := decoded.Read(reader); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	if reader.Remaining() != 0 {
+		t.Fatalf("%d bytes left after reading %d", reader.Remaining(), writer.GetLength())
+	}
+
+	if want := expected(sample, version); !reflect.DeepEqual(decoded, want) {
+		t.Fatalf("decoded %+v, want %+v", decoded, want)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for version := PROTOCOL_MIN_VERSION; version <= PROTOCOL_VERSION; version++ {
+		for _, sample := range samples {
+			t.Run("v"+strconv.Itoa(int(version))+"/"+sample.name, func(t *testing.T) {
+				roundTrip(t, sample.packet, version)
+			})
+		}
+	}
+}
+
+// v1 strings are a byte per rune, from v2 they are length prefixed UTF-8
+func TestRoundTripUTF8(t *testing.T) {
+	for version := uint16(2); version <= PROTOCOL_VERSION; version++ {
+		roundTrip(t, &ChatReceive{Channel: CHAT_CHANNEL_ALL, Sender: "Kapitän", Message: "Ahoy ⚓ 艦隊"}, version)
+	}
+}
+
+// Every struct in the schema has a sample, so a new packet cannot be added without coverage
+func TestSamplesCoverSchema(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "packets.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sampled map[string]bool = make(map[string]bool)
+	for _, sample := range samples {
+		sampled[reflect.TypeOf(sample.packet).Elem().Name()] = true
+	}
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		if spec, ok := node.(*ast.TypeSpec); ok {
+			if _, isStruct := spec.Type.(*ast.StructType); isStruct && !sampled[spec.Name.Name] {
+				t.Errorf("%s has no round trip sample", spec.Name.Name)
+			}
+		}
+
+		return true
+	})
+}
@@ -0,0 +1,60 @@
+package protocol
+
+import "math"
+
+// Maps a world coordinate in [-extent, extent] onto the full uint16 range
+func QuantizeCoordinate(value, extent float64) uint16 {
+	var t float64 = (value + extent) / (extent * 2)
+	return uint16(math.Round(max(0, min(1, t)) * math.MaxUint16))
+}
+
+func DequantizeCoordinate(value uint16, extent float64) float64 {
+	return float64(value)/math.MaxUint16*extent*2 - extent
+}
+
+// Maps an angle in radians onto a single byte
+func QuantizeAngle8(angle float64) uint8 {
+	var t float64 = math.Mod(angle, math.Pi*2)
+	if t < 0 {
+		t += math.Pi * 2
+	}
+
+	return uint8(int(math.Round(t/(math.Pi*2)*256)) % 256)
+}
+
+func DequantizeAngle8(value uint8) float64 {
+	return float64(value) / 256 * math.Pi * 2
+}
+
+const ANGLE12_STEPS int = 1 << 12
+
+// Maps an angle in radians onto 12 bits, about 0.09 degrees per step
+func QuantizeAngle12(angle float64) uint16 {
+	var t float64 = math.Mod(angle, math.Pi*2)
+	if t < 0 {
+		t += math.Pi * 2
+	}
+
+	return uint16(int(math.Round(t/(math.Pi*2)*float64(ANGLE12_STEPS))) % ANGLE12_STEPS)
+}
+
+// Returns an angle in [-pi, pi) to match the server's wrapped rotations
+func DequantizeAngle12(value uint16) float64 {
+	var angle float64 = float64(value%uint16(ANGLE12_STEPS)) / float64(ANGLE12_STEPS) * math.Pi * 2
+	if angle >= math.Pi {
+		angle -= math.Pi * 2
+	}
+
+	return angle
+}
+
+// Maps a world coordinate onto a 16 bit offset from the camera center, in steps of precision world
+// units. Offsets outside the range are clamped.
+func QuantizeOffset(value, center, precision float64) uint16 {
+	var steps float64 = math.Round((value - center) / precision)
+	return uint16(int32(max(math.MinInt16, min(math.MaxInt16, steps))) + 32768)
+}
+
+func DequantizeOffset(value uint16, center, precision float64) float64 {
+	return center + float64(int32(value)-32768)*precision
+}
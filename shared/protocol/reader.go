@@ -1,82 +1,301 @@
 package protocol
 
 import (
+	"bytes"
 	"encoding/binary"
+	"errors"
+	"fmt"
 	"math"
 	"strings"
+	"unicode/utf8"
 )
 
-type Reader struct { // Little endian
-	bytes  []byte
-	offset int
+const DEFAULT_MAX_STRING_LENGTH int = 1024 // In bytes, excluding the terminator or length prefix
+
+var (
+	ErrTruncated      error = errors.New("packet truncated")
+	ErrStringTooLong  error = errors.New("string too long")
+	ErrInvalidUTF8    error = errors.New("invalid UTF-8")
+	ErrVarintOverflow error = errors.New("varint overflow")
+)
+
+// Reads never panic. The first failure is kept in Err and every read after it returns zero values,
+// so a packet can be decoded in full and checked once at the end. See Writer for the wire format of
+// each version.
+type Reader struct {
+	bytes           []byte
+	offset          int
+	err             error
+	MaxStringLength int
+	Version         uint16 // 0 is treated as v1
 }
 
 func NewReader(bytes []byte) *Reader {
-	return &Reader{bytes, 0}
+	return &Reader{bytes: bytes, MaxStringLength: DEFAULT_MAX_STRING_LENGTH}
+}
+
+func (r *Reader) varints() bool {
+	return r.Version >= 2
+}
+
+func (r *Reader) Err() error {
+	return r.err
+}
+
+func (r *Reader) Remaining() int {
+	return len(r.bytes) - r.offset
+}
+
+func (r *Reader) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// Returns the next n bytes, or nil once the buffer is exhausted
+func (r *Reader) take(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+
+	if r.Remaining() < n {
+		r.fail(fmt.Errorf("%w: need %d bytes at offset %d, have %d", ErrTruncated, n, r.offset, r.Remaining()))
+		r.offset = len(r.bytes)
+		return nil
+	}
+
+	r.offset += n
+	return r.bytes[r.offset-n : r.offset]
+}
+
+// Clamps a decoded element count to the bytes left, every element takes at least one byte so a
+// forged count can't force a huge allocation
+func (r *Reader) Limit(count int) int {
+	if count > r.Remaining() {
+		r.fail(fmt.Errorf("%w: %d elements at offset %d, have %d bytes", ErrTruncated, count, r.offset, r.Remaining()))
+		return 0
+	}
+
+	return count
 }
 
 func (r *Reader) GetI8() int8 {
-	r.offset++
-	return int8(r.bytes[r.offset-1])
+	var b []byte = r.take(1)
+	if b == nil {
+		return 0
+	}
+
+	return int8(b[0])
 }
 
 func (r *Reader) GetI16() int16 {
-	r.offset += 2
-	return int16(r.bytes[r.offset-2])<<8 | int16(r.bytes[r.offset-1])
+	if r.varints() {
+		return int16(r.getSigned(math.MinInt16, math.MaxInt16))
+	}
+
+	var b []byte = r.take(2)
+	if b == nil {
+		return 0
+	}
+
+	return int16(b[0])<<8 | int16(b[1])
 }
 
 func (r *Reader) GetI32() int32 {
-	r.offset += 4
-	return int32(r.bytes[r.offset-4])<<24 | int32(r.bytes[r.offset-3])<<16 | int32(r.bytes[r.offset-2])<<8 | int32(r.bytes[r.offset-1])
+	if r.varints() {
+		return int32(r.getSigned(math.MinInt32, math.MaxInt32))
+	}
+
+	var b []byte = r.take(4)
+	if b == nil {
+		return 0
+	}
+
+	return int32(b[0])<<24 | int32(b[1])<<16 | int32(b[2])<<8 | int32(b[3])
 }
 
 func (r *Reader) GetI64() int64 {
-	r.offset += 8
-	return int64(r.bytes[r.offset-8])<<56 | int64(r.bytes[r.offset-7])<<48 | int64(r.bytes[r.offset-6])<<40 | int64(r.bytes[r.offset-5])<<32 | int64(r.bytes[r.offset-4])<<24 | int64(r.bytes[r.offset-3])<<16 | int64(r.bytes[r.offset-2])<<8 | int64(r.bytes[r.offset-1])
+	if r.varints() {
+		return r.GetVarint()
+	}
+
+	var b []byte = r.take(8)
+	if b == nil {
+		return 0
+	}
+
+	return int64(b[0])<<56 | int64(b[1])<<48 | int64(b[2])<<40 | int64(b[3])<<32 | int64(b[4])<<24 | int64(b[5])<<16 | int64(b[6])<<8 | int64(b[7])
 }
 
 func (r *Reader) GetU8() uint8 {
-	r.offset++
-	return r.bytes[r.offset-1]
+	var b []byte = r.take(1)
+	if b == nil {
+		return 0
+	}
+
+	return b[0]
 }
 
 func (r *Reader) GetU16() uint16 {
-	r.offset += 2
-	return binary.LittleEndian.Uint16(r.bytes[r.offset-2 : r.offset])
+	var b []byte = r.take(2)
+	if b == nil {
+		return 0
+	}
+
+	return binary.LittleEndian.Uint16(b)
 }
 
 func (r *Reader) GetU32() uint32 {
-	r.offset += 4
-	return binary.LittleEndian.Uint32(r.bytes[r.offset-4 : r.offset])
+	var b []byte = r.take(4)
+	if b == nil {
+		return 0
+	}
+
+	return binary.LittleEndian.Uint32(b)
 }
 
 func (r *Reader) GetU64() uint64 {
-	r.offset += 8
-	return binary.LittleEndian.Uint64(r.bytes[r.offset-8 : r.offset])
+	var b []byte = r.take(8)
+	if b == nil {
+		return 0
+	}
+
+	return binary.LittleEndian.Uint64(b)
+}
+
+func (r *Reader) GetUVarint() (value uint64) {
+	for shift := 0; shift < 64; shift += 7 {
+		var b []byte = r.take(1)
+		if b == nil {
+			return 0
+		}
+
+		// The tenth byte may only carry the last bit
+		if shift == 63 && b[0] > 1 {
+			break
+		}
+
+		value |= uint64(b[0]&0x7F) << shift
+		if b[0] < 0x80 {
+			return value
+		}
+	}
+
+	r.fail(fmt.Errorf("%w at offset %d", ErrVarintOverflow, r.offset))
+	return 0
+}
+
+func (r *Reader) GetVarint() int64 {
+	var value uint64 = r.GetUVarint()
+	return int64(value>>1) ^ -int64(value&1)
+}
+
+func (r *Reader) getSigned(low, high int64) int64 {
+	var value int64 = r.GetVarint()
+	if value < low || value > high {
+		r.fail(fmt.Errorf("%w: %d does not fit at offset %d", ErrVarintOverflow, value, r.offset))
+		return 0
+	}
+
+	return value
+}
+
+func (r *Reader) getUnsigned(limit uint64) uint64 {
+	var value uint64 = r.GetUVarint()
+	if value > limit {
+		r.fail(fmt.Errorf("%w: %d does not fit at offset %d", ErrVarintOverflow, value, r.offset))
+		return 0
+	}
+
+	return value
+}
+
+func (r *Reader) GetVarU8() uint8 {
+	if r.varints() {
+		return uint8(r.getUnsigned(math.MaxUint8))
+	}
+
+	return r.GetU8()
+}
+
+func (r *Reader) GetVarU16() uint16 {
+	if r.varints() {
+		return uint16(r.getUnsigned(math.MaxUint16))
+	}
+
+	return r.GetU16()
+}
+
+func (r *Reader) GetVarU32() uint32 {
+	if r.varints() {
+		return uint32(r.getUnsigned(math.MaxUint32))
+	}
+
+	return r.GetU32()
+}
+
+func (r *Reader) GetVarU64() uint64 {
+	if r.varints() {
+		return r.GetUVarint()
+	}
+
+	return r.GetU64()
 }
 
 func (r *Reader) GetF32() float32 {
-	r.offset += 4
-	return math.Float32frombits(binary.LittleEndian.Uint32(r.bytes[r.offset-4 : r.offset]))
+	return math.Float32frombits(r.GetU32())
 }
 
 func (r *Reader) GetF64() float64 {
-	r.offset += 8
-	return math.Float64frombits(binary.LittleEndian.Uint64(r.bytes[r.offset-8 : r.offset]))
+	return math.Float64frombits(r.GetU64())
 }
 
 func (r *Reader) GetStringUTF8() string {
-	var str strings.Builder
+	if r.err != nil {
+		return ""
+	}
 
-	for {
-		if r.bytes[r.offset] == 0 {
-			r.offset++
-			break
+	if r.varints() {
+		var length uint64 = r.GetUVarint()
+		if r.err == nil && length > uint64(r.MaxStringLength) {
+			r.fail(fmt.Errorf("%w: %d bytes at offset %d, limit is %d", ErrStringTooLong, length, r.offset, r.MaxStringLength))
+		}
+
+		if r.err != nil {
+			return ""
 		}
 
-		str.WriteString(string(r.bytes[r.offset]))
-		r.offset++
+		var b []byte = r.take(int(length))
+		if b == nil {
+			return ""
+		}
+
+		if !utf8.Valid(b) {
+			r.fail(fmt.Errorf("%w at offset %d", ErrInvalidUTF8, r.offset-len(b)))
+			return ""
+		}
+
+		return string(b)
+	}
+
+	var length int = bytes.IndexByte(r.bytes[r.offset:], 0)
+	if length < 0 {
+		r.fail(fmt.Errorf("%w: unterminated string at offset %d", ErrTruncated, r.offset))
+		r.offset = len(r.bytes)
+		return ""
 	}
 
+	if length > r.MaxStringLength {
+		r.fail(fmt.Errorf("%w: %d bytes at offset %d, limit is %d", ErrStringTooLong, length, r.offset, r.MaxStringLength))
+		r.offset = len(r.bytes)
+		return ""
+	}
+
+	var str strings.Builder
+	for _, b := range r.take(length) {
+		str.WriteString(string(b))
+	}
+
+	r.offset++
 	return str.String()
 }
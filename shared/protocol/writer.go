@@ -1,9 +1,24 @@
 package protocol
 
-import "math"
+import (
+	"math"
+	"unicode/utf8"
+)
+
+// Unsigned values are little endian. In v1 signed values are big endian and strings are one byte per
+// rune, null terminated. From v2 signed values are zig-zag varints, strings are length prefixed UTF-8
+// and the SetVar* methods write LEB128 varints instead of fixed width values.
+type Writer struct {
+	Bytes   []byte
+	Version uint16 // 0 is treated as v1
+}
+
+func NewWriter(version uint16) *Writer {
+	return &Writer{Version: version}
+}
 
-type Writer struct { // Little endian
-	Bytes []byte
+func (w *Writer) varints() bool {
+	return w.Version >= 2
 }
 
 func (w *Writer) SetI8(value int8) {
@@ -11,14 +26,29 @@ func (w *Writer) SetI8(value int8) {
 }
 
 func (w *Writer) SetI16(value int16) {
+	if w.varints() {
+		w.SetVarint(int64(value))
+		return
+	}
+
 	w.Bytes = append(w.Bytes, byte(value>>8), byte(value))
 }
 
 func (w *Writer) SetI32(value int32) {
+	if w.varints() {
+		w.SetVarint(int64(value))
+		return
+	}
+
 	w.Bytes = append(w.Bytes, byte(value>>24), byte(value>>16), byte(value>>8), byte(value))
 }
 
 func (w *Writer) SetI64(value int64) {
+	if w.varints() {
+		w.SetVarint(value)
+		return
+	}
+
 	w.Bytes = append(w.Bytes, byte(value>>56), byte(value>>48), byte(value>>40), byte(value>>32), byte(value>>24), byte(value>>16), byte(value>>8), byte(value))
 }
 
@@ -38,6 +68,58 @@ func (w *Writer) SetU64(value uint64) {
 	w.Bytes = append(w.Bytes, byte(value), byte(value>>8), byte(value>>16), byte(value>>24), byte(value>>32), byte(value>>40), byte(value>>48), byte(value>>56))
 }
 
+// LEB128, seven bits per byte with the high bit set on every byte but the last
+func (w *Writer) SetUVarint(value uint64) {
+	for value >= 0x80 {
+		w.Bytes = append(w.Bytes, byte(value)|0x80)
+		value >>= 7
+	}
+
+	w.Bytes = append(w.Bytes, byte(value))
+}
+
+// Zig-zag maps small negative numbers to small unsigned ones: 0, -1, 1, -2 -> 0, 1, 2, 3
+func (w *Writer) SetVarint(value int64) {
+	w.SetUVarint(uint64(value<<1) ^ uint64(value>>63))
+}
+
+// IDs and counts, fixed width in v1
+func (w *Writer) SetVarU8(value uint8) {
+	if w.varints() {
+		w.SetUVarint(uint64(value))
+		return
+	}
+
+	w.SetU8(value)
+}
+
+func (w *Writer) SetVarU16(value uint16) {
+	if w.varints() {
+		w.SetUVarint(uint64(value))
+		return
+	}
+
+	w.SetU16(value)
+}
+
+func (w *Writer) SetVarU32(value uint32) {
+	if w.varints() {
+		w.SetUVarint(uint64(value))
+		return
+	}
+
+	w.SetU32(value)
+}
+
+func (w *Writer) SetVarU64(value uint64) {
+	if w.varints() {
+		w.SetUVarint(value)
+		return
+	}
+
+	w.SetU64(value)
+}
+
 func (w *Writer) SetF32(value float32) {
 	w.SetU32(math.Float32bits(value))
 }
@@ -47,6 +129,16 @@ func (w *Writer) SetF64(value float64) {
 }
 
 func (w *Writer) SetStringUTF8(value string) {
+	if w.varints() {
+		if !utf8.ValidString(value) {
+			value = string([]rune(value))
+		}
+
+		w.SetUVarint(uint64(len(value)))
+		w.Bytes = append(w.Bytes, value...)
+		return
+	}
+
 	for _, char := range value {
 		w.SetU8(uint8(char))
 	}
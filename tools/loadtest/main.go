@@ -0,0 +1,245 @@
+// Spawns headless bots against a server and reports how it holds up: tick time as the bots see it, bytes
+// per client and connection failures.
+//
+//	go run ./tools/loadtest -server ws://localhost:3000/ws -bots 200 -ramp 10s -duration 1m -behavior chase
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/z46-dev/game-dev-project/client/bot"
+	"github.com/z46-dev/golog"
+)
+
+var log *golog.Logger = golog.New().Prefix("[LOADTEST]", golog.BoldBlue).Timestamp()
+
+type (
+	// Why bots did not make it through the run
+	Failures struct {
+		Dial, Rejected, Timeout, Dropped atomic.Int64
+	}
+
+	// Running totals across every bot, so each report can show the difference since the last one
+	Totals struct {
+		BytesIn, BytesOut, ViewUpdates int64
+		TickTotal, TickWorst           time.Duration
+		TickSamples                    int
+	}
+
+	Fleet struct {
+		mu       sync.Mutex
+		bots     []*bot.Bot
+		live     atomic.Int64
+		failures Failures
+		stop     chan struct{}
+		wg       sync.WaitGroup
+	}
+)
+
+var (
+	serverURL    string
+	botCount     int
+	ramp         time.Duration
+	duration     time.Duration
+	behaviorName string
+	shipID       uint
+	room         string
+	reportEvery  time.Duration
+)
+
+func behavior(index int, tickRate int) bot.Behavior {
+	switch behaviorName {
+	case "wander":
+		return bot.Wander(uint64(index), tickRate)
+	case "chase":
+		return bot.Chase(uint64(index), tickRate)
+	default:
+		return bot.Idle()
+	}
+}
+
+// Connects one bot and keeps it running until the fleet stops or the connection ends
+func (f *Fleet) spawn(index int) {
+	b, err := bot.Connect(bot.Config{
+		URL:    serverURL,
+		Name:   fmt.Sprintf("Bot %d", index),
+		Room:   room,
+		ShipID: uint8(shipID),
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, bot.ErrJoinTimeout):
+			f.failures.Timeout.Add(1)
+		case errors.Is(err, bot.ErrDial):
+			f.failures.Dial.Add(1)
+		default:
+			f.failures.Rejected.Add(1)
+		}
+
+		log.Warningf("Bot %d failed to join: %v", index, err)
+		return
+	}
+
+	b.Config.Behavior = behavior(index, b.TickRate)
+
+	f.mu.Lock()
+	f.bots = append(f.bots, b)
+	f.mu.Unlock()
+
+	f.live.Add(1)
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		defer f.live.Add(-1)
+
+		b.Run(f.stop)
+
+		select {
+		case <-f.stop:
+			b.Close()
+		default:
+			f.failures.Dropped.Add(1)
+			if b.Reason != "" {
+				log.Warningf("Bot %d was disconnected: %s", index, b.Reason)
+			} else {
+				log.Warningf("Bot %d lost its connection", index)
+			}
+		}
+	}()
+}
+
+func (f *Fleet) totals() (t Totals) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, b := range f.bots {
+		t.BytesIn += b.Stats.BytesIn.Load()
+		t.BytesOut += b.Stats.BytesOut.Load()
+		t.ViewUpdates += b.Stats.ViewUpdates.Load()
+
+		var total, worst, samples = b.Stats.TakeTickTimes()
+		t.TickTotal += total
+		t.TickWorst = max(t.TickWorst, worst)
+		t.TickSamples += samples
+	}
+
+	return
+}
+
+func (f *Fleet) report(previous Totals, elapsed time.Duration) (current Totals) {
+	current = f.totals()
+
+	var (
+		live    int64   = f.live.Load()
+		seconds float64 = elapsed.Seconds()
+		perBot  float64 = float64(max(1, live)) * seconds
+		mean    time.Duration
+	)
+
+	if current.TickSamples > 0 {
+		mean = current.TickTotal / time.Duration(current.TickSamples)
+	}
+
+	log.Infof("%d bots | down %.1f KB/s per bot | up %.0f B/s per bot | %.1f views/s per bot | tick mean %s worst %s | failed: %d dial, %d rejected, %d timeout, %d dropped",
+		live,
+		float64(current.BytesIn-previous.BytesIn)/1024/perBot,
+		float64(current.BytesOut-previous.BytesOut)/perBot,
+		float64(current.ViewUpdates-previous.ViewUpdates)/perBot,
+		mean.Round(time.Microsecond), current.TickWorst.Round(time.Microsecond),
+		f.failures.Dial.Load(), f.failures.Rejected.Load(), f.failures.Timeout.Load(), f.failures.Dropped.Load())
+
+	return
+}
+
+func main() {
+	flag.StringVar(&serverURL, "server", "ws://localhost:3000/ws", "Server WebSocket URL")
+	flag.IntVar(&botCount, "bots", 50, "Number of bots to connect")
+	flag.DurationVar(&ramp, "ramp", 5*time.Second, "Time to spread the connections over")
+	flag.DurationVar(&duration, "duration", 30*time.Second, "How long to run once the bots are spawning, 0 until interrupted")
+	flag.StringVar(&behaviorName, "behavior", "wander", "Bot behavior: idle, wander or chase")
+	flag.UintVar(&shipID, "ship", 0, "Ship ID every bot joins with, guests can only take starter ships")
+	flag.StringVar(&room, "room", "default", "Room to join")
+	flag.DurationVar(&reportEvery, "report", 5*time.Second, "How often to print stats")
+	flag.Parse()
+
+	switch behaviorName {
+	case "idle", "wander", "chase":
+	default:
+		log.Warningf("Unknown behavior %q", behaviorName)
+		os.Exit(2)
+	}
+
+	var fleet *Fleet = &Fleet{stop: make(chan struct{})}
+
+	log.Infof("Connecting %d bots to %s over %s", botCount, serverURL, ramp)
+
+	var spawning sync.WaitGroup
+	go func() {
+		var gap time.Duration = ramp / time.Duration(max(1, botCount))
+		for i := range botCount {
+			select {
+			case <-fleet.stop:
+				return
+			default:
+			}
+
+			spawning.Add(1)
+			go func(index int) {
+				defer spawning.Done()
+				fleet.spawn(index)
+			}(i + 1)
+
+			time.Sleep(gap)
+		}
+	}()
+
+	var (
+		ticker   *time.Ticker = time.NewTicker(reportEvery)
+		started  time.Time    = time.Now()
+		last     time.Time    = started
+		previous Totals
+		end      <-chan time.Time
+	)
+
+	defer ticker.Stop()
+
+	if duration > 0 {
+		end = time.After(duration)
+	}
+
+	for running := true; running; {
+		select {
+		case now := <-ticker.C:
+			previous = fleet.report(previous, now.Sub(last))
+			last = now
+		case <-end:
+			running = false
+		}
+	}
+
+	close(fleet.stop)
+	spawning.Wait()
+	fleet.wg.Wait()
+
+	var (
+		totals  Totals  = fleet.totals()
+		seconds float64 = time.Since(started).Seconds()
+		joined  int     = len(fleet.bots)
+	)
+
+	log.Infof("Done after %s: %d of %d bots joined", time.Since(started).Round(time.Second), joined, botCount)
+	if joined > 0 {
+		log.Infof("Average per bot: down %.1f KB/s, up %.0f B/s",
+			float64(totals.BytesIn)/1024/float64(joined)/seconds, float64(totals.BytesOut)/float64(joined)/seconds)
+	}
+
+	log.Infof("Failures: %d dial, %d rejected, %d timeout, %d dropped",
+		fleet.failures.Dial.Load(), fleet.failures.Rejected.Load(), fleet.failures.Timeout.Load(), fleet.failures.Dropped.Load())
+}
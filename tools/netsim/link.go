@@ -0,0 +1,202 @@
+package main
+
+import (
+	"container/heap"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+type (
+	// What one direction of every connection goes through
+	Conditions struct {
+		Latency, Jitter time.Duration // Base one way delay, plus up to Jitter more at random
+		ReorderChance   float64       // Fraction of messages held back so later ones overtake them
+		ReorderDelay    time.Duration // How long a reordered message is held back
+		Bandwidth       int           // Bytes per second, 0 for unlimited
+		DisconnectEvery time.Duration // Mean time between forced disconnects, 0 for never
+	}
+
+	// Totals for one direction across all connections, printed and reset every stats interval
+	Counters struct {
+		Messages, Bytes, Reordered, Queued atomic.Int64
+	}
+
+	message struct {
+		at       time.Time
+		order    uint64
+		kind     int
+		data     []byte
+		reorders bool
+	}
+
+	messageQueue []*message
+
+	// One direction of one proxied connection. Messages are read as fast as they arrive and written once due.
+	pipe struct {
+		from, to   *websocket.Conn
+		conditions *Conditions
+		counters   *Counters
+		mu         sync.Mutex
+		queue      messageQueue
+		wake       chan struct{}
+		last       time.Time // Due time of the newest in-order message, jitter never lets one pass another
+		order      uint64
+		closed     bool
+	}
+)
+
+func (q messageQueue) Len() int { return len(q) }
+func (q messageQueue) Less(i, j int) bool {
+	if q[i].at.Equal(q[j].at) {
+		return q[i].order < q[j].order
+	}
+
+	return q[i].at.Before(q[j].at)
+}
+func (q messageQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *messageQueue) Push(x any)   { *q = append(*q, x.(*message)) }
+func (q *messageQueue) Pop() (x any) {
+	var old messageQueue = *q
+	x = old[len(old)-1]
+	*q = old[:len(old)-1]
+	return
+}
+
+func newPipe(from, to *websocket.Conn, conditions *Conditions, counters *Counters) *pipe {
+	return &pipe{
+		from:       from,
+		to:         to,
+		conditions: conditions,
+		counters:   counters,
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+// When a message read now should be delivered. In-order messages keep their order like TCP would, so jitter
+// only stretches the gaps between them; reordered ones are scheduled on their own and can fall behind.
+func (p *pipe) schedule(m *message, now time.Time) {
+	var c *Conditions = p.conditions
+	m.at = now.Add(c.Latency)
+	if c.Jitter > 0 {
+		m.at = m.at.Add(time.Duration(rand.Int64N(int64(c.Jitter))))
+	}
+
+	if m.reorders = c.ReorderChance > 0 && rand.Float64() < c.ReorderChance; m.reorders {
+		m.at = m.at.Add(c.ReorderDelay)
+		return
+	}
+
+	if m.at.Before(p.last) {
+		m.at = p.last
+	}
+
+	p.last = m.at
+}
+
+// Reads until the connection fails, queueing every message for the writer
+func (p *pipe) read(done chan<- struct{}) {
+	defer close(done)
+
+	for {
+		kind, data, err := p.from.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return
+		}
+
+		p.order++
+		var m *message = &message{kind: kind, data: data, order: p.order}
+		p.schedule(m, time.Now())
+		heap.Push(&p.queue, m)
+		p.mu.Unlock()
+
+		p.counters.Queued.Add(1)
+		select {
+		case p.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Writes queued messages as they come due, no faster than the bandwidth allows
+func (p *pipe) write(stop <-chan struct{}) {
+	var (
+		timer    *time.Timer = time.NewTimer(time.Hour)
+		nextFree time.Time
+	)
+
+	defer timer.Stop()
+
+	// Whatever is still in flight is lost with the connection
+	defer func() {
+		p.mu.Lock()
+		p.closed = true
+		p.counters.Queued.Add(-int64(len(p.queue)))
+		p.queue = nil
+		p.mu.Unlock()
+	}()
+
+	for {
+		var wait time.Duration = time.Hour
+		p.mu.Lock()
+		if len(p.queue) > 0 {
+			var due time.Time = p.queue[0].at
+			if nextFree.After(due) {
+				due = nextFree
+			}
+
+			wait = time.Until(due)
+		}
+		p.mu.Unlock()
+
+		if wait <= 0 {
+			p.mu.Lock()
+			var m *message = heap.Pop(&p.queue).(*message)
+			p.mu.Unlock()
+
+			p.counters.Queued.Add(-1)
+			if err := p.to.WriteMessage(m.kind, m.data); err != nil {
+				return
+			}
+
+			p.counters.Messages.Add(1)
+			p.counters.Bytes.Add(int64(len(m.data)))
+			if m.reorders {
+				p.counters.Reordered.Add(1)
+			}
+
+			if p.conditions.Bandwidth > 0 {
+				nextFree = time.Now().Add(time.Duration(float64(len(m.data)) / float64(p.conditions.Bandwidth) * float64(time.Second)))
+			}
+
+			continue
+		}
+
+		timer.Reset(wait)
+		select {
+		case <-stop:
+			return
+		case <-p.wake:
+		case <-timer.C:
+		}
+	}
+}
+
+// How long until this direction forcibly drops the connection, exponentially distributed around the mean
+func (c *Conditions) disconnectAfter() (after time.Duration, ok bool) {
+	if c.DisconnectEvery <= 0 {
+		return
+	}
+
+	return time.Duration(-math.Log(1-rand.Float64()) * float64(c.DisconnectEvery)), true
+}
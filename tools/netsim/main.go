@@ -0,0 +1,138 @@
+// WebSocket proxy that puts latency, jitter, reordering, bandwidth caps and disconnects between a client
+// and the server, for testing interpolation, prediction and reconnects on one machine:
+//
+//	go run ./tools/netsim -listen :3001 -target ws://localhost:3000/ws -down-latency 80ms -down-jitter 30ms
+//	go run ./client -server ws://localhost:3001/ws
+package main
+
+import (
+	"flag"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/z46-dev/golog"
+)
+
+var (
+	log      *golog.Logger = golog.New().Prefix("[NETSIM]", golog.BoldBlue).Timestamp()
+	upgrader               = websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }}
+)
+
+var (
+	target       string
+	up, down     Conditions // Client to server, server to client
+	upCounters   Counters
+	downCounters Counters
+	connections  atomic.Int64
+	nextID       atomic.Int64
+)
+
+func directionFlags(prefix, name string, c *Conditions) {
+	flag.DurationVar(&c.Latency, prefix+"-latency", 0, "One way delay "+name)
+	flag.DurationVar(&c.Jitter, prefix+"-jitter", 0, "Random extra delay "+name+", up to this much")
+	flag.Float64Var(&c.ReorderChance, prefix+"-reorder", 0, "Fraction of messages "+name+" held back so later ones overtake them")
+	flag.DurationVar(&c.ReorderDelay, prefix+"-reorder-delay", 50*time.Millisecond, "How long a reordered message "+name+" is held back")
+	flag.IntVar(&c.Bandwidth, prefix+"-bandwidth", 0, "Bytes per second "+name+" per connection, 0 for unlimited")
+	flag.DurationVar(&c.DisconnectEvery, prefix+"-disconnect", 0, "Mean time before the connection is cut "+name+", 0 for never")
+}
+
+// Proxies one client connection until either side fails or a simulated disconnect cuts it
+func handle(writer http.ResponseWriter, request *http.Request) {
+	var id int64 = nextID.Add(1)
+
+	client, err := upgrader.Upgrade(writer, request, nil)
+	if err != nil {
+		log.Warningf("#%d upgrade failed: %v", id, err)
+		return
+	}
+
+	defer client.Close()
+
+	server, _, err := websocket.DefaultDialer.Dial(target, nil)
+	if err != nil {
+		log.Warningf("#%d could not reach %s: %v", id, target, err)
+		return
+	}
+
+	defer server.Close()
+
+	connections.Add(1)
+	defer connections.Add(-1)
+	log.Infof("#%d connected from %s", id, request.RemoteAddr)
+
+	var (
+		upPipe   *pipe         = newPipe(client, server, &up, &upCounters)
+		downPipe *pipe         = newPipe(server, client, &down, &downCounters)
+		upDone   chan struct{} = make(chan struct{})
+		downDone chan struct{} = make(chan struct{})
+		stop     chan struct{} = make(chan struct{})
+		cut      <-chan time.Time
+		reason   string
+	)
+
+	go upPipe.read(upDone)
+	go downPipe.read(downDone)
+	go upPipe.write(stop)
+	go downPipe.write(stop)
+
+	// Whichever direction is due to fail first takes the connection down
+	var soonest time.Duration = -1
+	for _, direction := range []struct {
+		name       string
+		conditions *Conditions
+	}{{"upstream", &up}, {"downstream", &down}} {
+		if after, ok := direction.conditions.disconnectAfter(); ok && (soonest < 0 || after < soonest) {
+			soonest, reason = after, "simulated "+direction.name+" disconnect"
+		}
+	}
+
+	if soonest >= 0 {
+		cut = time.After(soonest)
+	}
+
+	select {
+	case <-upDone:
+		reason = "client closed"
+	case <-downDone:
+		reason = "server closed"
+	case <-cut:
+	}
+
+	close(stop)
+	log.Infof("#%d %s", id, reason)
+}
+
+func printStats(interval time.Duration) {
+	var seconds float64 = interval.Seconds()
+	for range time.Tick(interval) {
+		log.Infof("%d conn | up %6.1f msg/s %8.2f KB/s %3d queued %3d reordered | down %6.1f msg/s %8.2f KB/s %3d queued %3d reordered",
+			connections.Load(),
+			float64(upCounters.Messages.Swap(0))/seconds, float64(upCounters.Bytes.Swap(0))/1024/seconds, upCounters.Queued.Load(), upCounters.Reordered.Swap(0),
+			float64(downCounters.Messages.Swap(0))/seconds, float64(downCounters.Bytes.Swap(0))/1024/seconds, downCounters.Queued.Load(), downCounters.Reordered.Swap(0),
+		)
+	}
+}
+
+func main() {
+	var (
+		listen *string        = flag.String("listen", ":3001", "Address clients connect to")
+		stats  *time.Duration = flag.Duration("stats", time.Second, "How often throughput is printed, 0 to stay quiet")
+	)
+
+	flag.StringVar(&target, "target", "ws://localhost:3000/ws", "Server WebSocket address")
+	directionFlags("up", "client to server", &up)
+	directionFlags("down", "server to client", &down)
+	flag.Parse()
+
+	if *stats > 0 {
+		go printStats(*stats)
+	}
+
+	http.HandleFunc("/", handle)
+	log.Infof("Proxying %s to %s (up %+v, down %+v)", *listen, target, up, down)
+	if err := http.ListenAndServe(*listen, nil); err != nil {
+		log.Panicf("Could not listen on %s: %v", *listen, err)
+	}
+}
@@ -0,0 +1,125 @@
+// Re-runs a match from the server's input log (game.input_log in game_server.toml) tick for tick and checks
+// the state hashes it logged, to reproduce desyncs and physics bugs:
+//
+//	go run ./tools/replay -log match.log
+//	go run ./tools/replay -log match.log -until 5400
+package main
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"time"
+
+	"github.com/z46-dev/game-dev-project/server/game"
+	"github.com/z46-dev/game-dev-project/shared/definitions"
+	"github.com/z46-dev/golog"
+)
+
+var log *golog.Logger = golog.New().Prefix("[REPLAY]", golog.BoldBlue).Timestamp()
+
+func main() {
+	var (
+		path    *string = flag.String("log", "", "Input log to replay")
+		until   *int    = flag.Int("until", 0, "Stop after this tick, 0 for the whole log")
+		verbose *bool   = flag.Bool("verbose", false, "Print every verified hash")
+	)
+
+	flag.Parse()
+
+	if *path == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	reader, err := game.OpenInputLog(*path)
+	if err != nil {
+		log.Panicf("Could not open log: %v", err)
+	}
+
+	defer reader.Close()
+
+	var header game.InputLogHeader = reader.Header
+	log.Infof("Match started %s with seed %d at %d TPS", header.Started.Format(time.DateTime), header.Seed, header.TPS)
+
+	if header.TPS != game.TPS {
+		log.Warningf("Logged at %d TPS but the simulation runs at %d, hashes will not match", header.TPS, game.TPS)
+	}
+
+	if header.DefinitionsHash != definitions.Hash() {
+		log.Warningf("Ship definitions changed since the log was written (%08x, now %08x), hashes will likely not match", header.DefinitionsHash, definitions.Hash())
+	}
+
+	if header.Snapshot != nil {
+		log.Infof("Restored from a snapshot saved %s at tick %d", header.Snapshot.Saved.Format(time.DateTime), header.Snapshot.Time)
+	}
+
+	replay, err := game.NewReplay(&header)
+	if err != nil {
+		log.Panicf("Could not rebuild the world: %v", err)
+	}
+
+	var (
+		started  time.Time = time.Now()
+		first    int       = replay.Game.Time()
+		events   int
+		verified int
+	)
+
+	for {
+		line, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			log.Warningf("Stopping at a damaged line after tick %d: %v", replay.Game.Time(), err)
+			break
+		}
+
+		var tick int
+		switch {
+		case line.Event != nil:
+			tick = line.Event.Tick
+		case line.Checkpoint != nil:
+			tick = line.Checkpoint.Tick
+		default:
+			continue
+		}
+
+		if *until > 0 && tick > *until {
+			replay.AdvanceTo(*until)
+			break
+		}
+
+		if line.Event != nil {
+			if err = replay.Apply(line.Event); err != nil {
+				log.Errorf("Replay failed: %v", err)
+				os.Exit(1)
+			}
+
+			events++
+			continue
+		}
+
+		hash, ok, err := replay.Verify(line.Checkpoint)
+		if err != nil {
+			log.Errorf("Replay failed: %v", err)
+			os.Exit(1)
+		}
+
+		if !ok {
+			log.Errorf("Desync at tick %d: logged %016x, replayed %016x (%d hashes matched before it)", line.Checkpoint.Tick, line.Checkpoint.Hash, hash, verified)
+			os.Exit(1)
+		}
+
+		verified++
+		if *verbose {
+			log.Infof("Tick %d: %016x", line.Checkpoint.Tick, hash)
+		}
+	}
+
+	log.Infof("Replayed %d ticks and %d events in %s, all %d hashes matched, final state %016x",
+		replay.Game.Time()-first, events, time.Since(started).Round(time.Millisecond), verified, replay.Game.StateHash())
+}
@@ -39,26 +39,26 @@ func AngleBetween(source, destination *Vector2D) float64 {
 	return math.Atan2(destination.Y-source.Y, destination.X-source.X)
 }
 
-// RandomRange returns a random float64 between min and max
+// RandomRange returns a random float64 between min and max, drawn from rng
 //
 // If min is greater than max, they are swapped
-func RandomRange(min, max float64) float64 {
+func RandomRange(rng *rand.Rand, min, max float64) float64 {
 	if min > max {
 		min, max = max, min
 	}
 
-	return min + (max-min)*rand.Float64()
+	return min + (max-min)*rng.Float64()
 }
 
-// RandomRangeInt returns a random int between min and max
+// RandomRangeInt returns a random int between min and max, drawn from rng
 //
 // If min is greater than max, they are swapped
-func RandomRangeInt(min, max int) int {
+func RandomRangeInt(rng *rand.Rand, min, max int) int {
 	if min > max {
 		min, max = max, min
 	}
 
-	return min + rand.IntN(max-min+1)
+	return min + rng.IntN(max-min+1)
 }
 
 // AngleDifference returns the smallest difference between two angles in radians
@@ -134,14 +134,14 @@ func NearColorHueOnly(col color.Color, randomThresh float64) color.RGBA {
 	return color.RGBA{R: R, G: G, B: B, A: A}
 }
 
-func RandomAngularVector(minStrength, maxStrength float64) *Vector2D {
+func RandomAngularVector(rng *rand.Rand, minStrength, maxStrength float64) *Vector2D {
 	// strength := RandomRange(minStrength, maxStrength)
 	// angle := rand.Float64() * 2 * math.Pi
 	// return strength * math.Cos(angle), strength * math.Sin(angle)
 
 	var (
-		angle    float64 = rand.Float64() * 2 * math.Pi
-		strength float64 = RandomRange(minStrength, maxStrength)
+		angle    float64 = rng.Float64() * 2 * math.Pi
+		strength float64 = RandomRange(rng, minStrength, maxStrength)
 	)
 
 	return &Vector2D{
@@ -150,10 +150,10 @@ func RandomAngularVector(minStrength, maxStrength float64) *Vector2D {
 	}
 }
 
-func RandomRadius(radius float64) *Vector2D {
+func RandomRadius(rng *rand.Rand, radius float64) *Vector2D {
 	var (
-		angle    float64 = rand.Float64() * 2 * math.Pi
-		distance float64 = rand.Float64() * radius
+		angle    float64 = rng.Float64() * 2 * math.Pi
+		distance float64 = rng.Float64() * radius
 	)
 
 	return &Vector2D{
@@ -448,3 +448,37 @@ func resolveTwoPolygonsConvex(p1, p2 *Polygon) (resolution *Vector2D) {
 
 	return mtv
 }
+
+// SegmentsIntersect reports whether the segments a1-a2 and b1-b2 cross or touch
+func SegmentsIntersect(a1, a2, b1, b2 *Vector2D) (intersects bool) {
+	var (
+		d1 float64 = (b2.X-b1.X)*(a1.Y-b1.Y) - (b2.Y-b1.Y)*(a1.X-b1.X)
+		d2 float64 = (b2.X-b1.X)*(a2.Y-b1.Y) - (b2.Y-b1.Y)*(a2.X-b1.X)
+		d3 float64 = (a2.X-a1.X)*(b1.Y-a1.Y) - (a2.Y-a1.Y)*(b1.X-a1.X)
+		d4 float64 = (a2.X-a1.X)*(b2.Y-a1.Y) - (a2.Y-a1.Y)*(b2.X-a1.X)
+	)
+
+	intersects = ((d1 > 0) != (d2 > 0)) && ((d3 > 0) != (d4 > 0))
+	return
+}
+
+// SegmentIntersects reports whether the segment a-b crosses any edge of the polygon or lies inside it
+func (p *Polygon) SegmentIntersects(a, b *Vector2D) (intersects bool) {
+	if p.numPoints == 0 {
+		return
+	}
+
+	var segment *AABB = &AABB{X1: min(a.X, b.X), Y1: min(a.Y, b.Y), X2: max(a.X, b.X), Y2: max(a.Y, b.Y)}
+	if !segment.Intersects(p.AABB) {
+		return
+	}
+
+	for i := range p.numPoints {
+		if intersects = SegmentsIntersect(a, b, p.Points[i], p.Points[(i+1)%p.numPoints]); intersects {
+			return
+		}
+	}
+
+	intersects = p.PointIsInside(a)
+	return
+}
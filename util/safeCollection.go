@@ -1,6 +1,10 @@
 package util
 
-import "sync"
+import (
+	"maps"
+	"slices"
+	"sync"
+)
 
 type Identifiable interface {
 	GetID() uint64
@@ -8,6 +12,7 @@ type Identifiable interface {
 
 type SafeStorage[T Identifiable] struct {
 	storage           map[uint64]T
+	order             []uint64 // Keys in ascending order, so ForEach visits items the same way every run
 	mu                sync.RWMutex
 	enqueuedAdditions []T
 	enqueuedRemovals  []uint64
@@ -36,6 +41,8 @@ func (ss *SafeStorage[T]) Flush() {
 	ss.mu.Lock()
 	defer ss.mu.Unlock()
 
+	var changed bool = len(ss.enqueuedAdditions) > 0 || len(ss.enqueuedRemovals) > 0
+
 	// Add enqueued items
 	for _, item := range ss.enqueuedAdditions {
 		ss.storage[item.GetID()] = item
@@ -49,6 +56,10 @@ func (ss *SafeStorage[T]) Flush() {
 	}
 
 	ss.enqueuedRemovals = ss.enqueuedRemovals[:0]
+
+	if changed {
+		ss.order = slices.Sorted(maps.Keys(ss.storage))
+	}
 }
 
 // Retrieves an item from the storage by its ID. Returns nil if the item does not exist.
@@ -59,16 +70,37 @@ func (ss *SafeStorage[T]) Get(id uint64) T {
 	return ss.storage[id]
 }
 
-// Iterates over all items in the storage and applies the provided function to each item.
+// Iterates over all items in the storage in ID order and applies the provided function to each item.
 func (ss *SafeStorage[T]) ForEach(f func(T)) {
 	ss.mu.RLock()
 	defer ss.mu.RUnlock()
 
-	for _, item := range ss.storage {
-		f(item)
+	for _, id := range ss.order {
+		f(ss.storage[id])
 	}
 }
 
+// Returns the items the storage will hold after the next flush, in ID order.
+func (ss *SafeStorage[T]) Pending() (items []T) {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+
+	var merged map[uint64]T = maps.Clone(ss.storage)
+	for _, item := range ss.enqueuedAdditions {
+		merged[item.GetID()] = item
+	}
+
+	for _, id := range ss.enqueuedRemovals {
+		delete(merged, id)
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(merged)) {
+		items = append(items, merged[id])
+	}
+
+	return
+}
+
 // Returns the number of items in the storage.
 func (ss *SafeStorage[T]) Size() int {
 	ss.mu.RLock()
@@ -85,4 +117,6 @@ func (ss *SafeStorage[T]) Clear() {
 	for key := range ss.storage {
 		delete(ss.storage, key)
 	}
+
+	ss.order = ss.order[:0]
 }